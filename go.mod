@@ -1 +1,5 @@
 module github.com/apparentlymart/go-paths
+
+go 1.23
+
+require golang.org/x/text v0.21.0
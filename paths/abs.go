@@ -0,0 +1,82 @@
+package paths
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Abs returns an absolute representation of the given path, resolving it
+// against the given working directory if it is not already absolute.
+//
+// Unlike filepath.Abs, this function is purely lexical: it never consults
+// the local system, and so the caller must provide the working directory of
+// the target system. The working directory must itself be absolute, or
+// Abs will return an error. The result is cleaned, as with Clean.
+//
+// For Windows, Abs is equivalent to WindowsAbs with no per-drive working
+// directories, so a path relative to the current directory of some other
+// drive, such as "D:foo", is resolved against the root of that drive.
+func Abs(p P, cwd, path string) (string, error) {
+	if syntaxOf(p) == windowsImpl {
+		return WindowsAbs(cwd, nil, path)
+	}
+	if !p.IsAbs(cwd) {
+		return "", fmt.Errorf("working directory %s is not absolute", cwd)
+	}
+	if p.IsAbs(path) {
+		return p.Clean(path), nil
+	}
+	return p.Join(cwd, path), nil
+}
+
+// WindowsAbs is a variant of Abs for Windows paths that additionally accepts
+// the working directories of drives other than the current one.
+//
+// Windows tracks a separate working directory for each drive, and the
+// GetFullPathNameW function resolves paths against them as follows:
+//
+//	foo      is relative to the working directory, cwd
+//	\foo     is relative to the root of the volume of cwd
+//	D:foo    is relative to the working directory of drive D
+//	D:\foo   is already absolute
+//
+// The drives map gives the working directory for drives other than the
+// drive of cwd, with keys like "D:". The keys are matched case-insensitively
+// and the values must be absolute paths. A drive-relative path for a drive
+// that is not in the map is resolved against the root of that drive.
+//
+// As with Windows.IsAbs, the reserved device names such as "NUL" are
+// considered to be already absolute and are returned unchanged.
+func WindowsAbs(cwd string, drives map[string]string, path string) (string, error) {
+	if !Windows.IsAbs(cwd) {
+		return "", fmt.Errorf("working directory %s is not absolute", cwd)
+	}
+	if Windows.IsAbs(path) {
+		return Windows.Clean(path), nil
+	}
+
+	vol := Windows.VolumeName(path)
+	rest := path[len(vol):]
+	cwdVol := Windows.VolumeName(cwd)
+	switch {
+	case vol == "" && rest != "" && isSlash(rest[0]):
+		// Rooted path on the current volume
+		return Windows.Join(cwdVol, rest), nil
+	case vol == "" || strings.EqualFold(vol, cwdVol):
+		// Relative to the working directory
+		return Windows.Join(cwd, rest), nil
+	}
+
+	// If we get here then we have a path relative to the current directory
+	// on some other drive.
+	for drive, dir := range drives {
+		if !strings.EqualFold(drive, vol) {
+			continue
+		}
+		if !Windows.IsAbs(dir) || !strings.EqualFold(Windows.VolumeName(dir), vol) {
+			return "", fmt.Errorf("working directory %s for drive %s is not an absolute path on that drive", dir, drive)
+		}
+		return Windows.Join(dir, rest), nil
+	}
+	return Windows.Join(vol+`\`, rest), nil
+}
@@ -0,0 +1,114 @@
+package paths
+
+import (
+	"testing"
+)
+
+func TestAbs(t *testing.T) {
+	type Test struct {
+		cwd, path, want string
+	}
+
+	implTests := map[string][]Test{
+		"Unix": {
+			{"/home/alice", "proj", "/home/alice/proj"},
+			{"/home/alice", "./proj/../src/", "/home/alice/src"},
+			{"/home/alice", "", "/home/alice"},
+			{"/home/alice", ".", "/home/alice"},
+			{"/home/alice", "../..", "/"},
+			{"/home/alice", "../../..", "/"},
+			{"/home/alice", "/etc//hosts", "/etc/hosts"},
+			{"/", "etc", "/etc"},
+			{"home/alice", "proj", "err"},
+			{"", "proj", "err"},
+		},
+		"Windows": {
+			{`C:\Users\Alice`, `proj`, `C:\Users\Alice\proj`},
+			{`C:\Users\Alice`, `proj/src/..`, `C:\Users\Alice\proj`},
+			{`C:\Users\Alice`, ``, `C:\Users\Alice`},
+			{`C:\Users\Alice`, `..\..\..`, `C:\`},
+			{`C:\Users\Alice`, `\Windows`, `C:\Windows`},
+			{`C:\Users\Alice`, `/Windows`, `C:\Windows`},
+			{`C:\Users\Alice`, `D:\data`, `D:\data`},
+			{`C:\Users\Alice`, `D:data`, `D:\data`},
+			{`C:\Users\Alice`, `D:`, `D:\`},
+			{`C:\Users\Alice`, `c:proj`, `C:\Users\Alice\proj`},
+			{`C:\Users\Alice`, `\\server\share\x\..\y`, `\\server\share\y`},
+			{`\\server\share\home`, `proj`, `\\server\share\home\proj`},
+			{`\\server\share\home`, `\proj`, `\\server\share\proj`},
+			{`\\server\share\home`, `..\..`, `\\server\share\`},
+			{`C:\Users\Alice`, `NUL`, `NUL`},
+			{`Users\Alice`, `proj`, `err`},
+			{`\Users\Alice`, `proj`, `err`},
+			{`C:Users`, `proj`, `err`},
+		},
+	}
+
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Windows": Windows,
+	}
+
+	for implName, tests := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			for _, test := range tests {
+				t.Run(test.cwd+" "+test.path, func(t *testing.T) {
+					got, err := Abs(p, test.cwd, test.path)
+					if test.want == "err" {
+						if err == nil {
+							t.Errorf("wrong result for %s Abs(%q, %q)\ngot:  %s\nwant: an error", implName, test.cwd, test.path, got)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error for %s Abs(%q, %q): %s", implName, test.cwd, test.path, err)
+					}
+					if got != test.want {
+						t.Errorf("wrong result for %s Abs(%q, %q)\ngot:  %s\nwant: %s", implName, test.cwd, test.path, got, test.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestWindowsAbs(t *testing.T) {
+	drives := map[string]string{
+		"d:": `D:\work\src`,
+		"E:": `E:\`,
+		"F:": `C:\wrong`,
+	}
+	tests := []struct {
+		cwd, path, want string
+	}{
+		{`C:\Users\Alice`, `D:foo`, `D:\work\src\foo`},
+		{`C:\Users\Alice`, `d:..\bin`, `D:\work\bin`},
+		{`C:\Users\Alice`, `D:`, `D:\work\src`},
+		{`C:\Users\Alice`, `D:\foo`, `D:\foo`},
+		{`C:\Users\Alice`, `E:foo`, `E:\foo`},
+		{`C:\Users\Alice`, `G:foo`, `G:\foo`},
+		{`C:\Users\Alice`, `C:foo`, `C:\Users\Alice\foo`},
+		{`C:\Users\Alice`, `\foo`, `C:\foo`},
+		{`D:\elsewhere`, `D:foo`, `D:\elsewhere\foo`},
+		{`C:\Users\Alice`, `F:foo`, `err`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.cwd+" "+test.path, func(t *testing.T) {
+			got, err := WindowsAbs(test.cwd, drives, test.path)
+			if test.want == "err" {
+				if err == nil {
+					t.Errorf("wrong result for WindowsAbs(%q, %q)\ngot:  %s\nwant: an error", test.cwd, test.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for WindowsAbs(%q, %q): %s", test.cwd, test.path, err)
+			}
+			if got != test.want {
+				t.Errorf("wrong result for WindowsAbs(%q, %q)\ngot:  %s\nwant: %s", test.cwd, test.path, got, test.want)
+			}
+		})
+	}
+}
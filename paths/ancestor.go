@@ -0,0 +1,80 @@
+package paths
+
+// CommonAncestor returns the deepest path that is the same as or an ancestor
+// of every one of the given paths, comparing whole components after cleaning
+// them using the case rules of the given path implementation. The result is
+// cleaned and uses the spelling of the first path.
+//
+// For example, the common ancestor of "/srv/out/a/x.o" and "/srv/out/b" is
+// "/srv/out", and for Windows the common ancestor of `C:\Build\a` and
+// `c:/build/b` is `C:\Build`.
+//
+// The second return value is false if there are no paths, or if the paths
+// have different volume names or mix rooted and relative forms, since such
+// paths have no common ancestor that can be expressed as a path. Relative
+// paths have at least the common ancestor ".", or ".." for paths like "../a"
+// that are outside of the current directory.
+//
+// For ObjectKey, keys are split as described for HasPathPrefix, and the
+// result is the empty key if the keys have no common parts.
+func CommonAncestor(p P, paths ...string) (string, bool) {
+	if len(paths) == 0 {
+		return "", false
+	}
+	vol, rooted, elems := splitComponents(p, paths[0])
+	n := len(elems)
+	dotdots := leadingDotDots(p, elems)
+	for _, path := range paths[1:] {
+		otherVol, otherRooted, otherElems := splitComponents(p, path)
+		if otherRooted != rooted || !sameWord(p, otherVol, vol) {
+			return "", false
+		}
+		if len(otherElems) < n {
+			n = len(otherElems)
+		}
+		for i := 0; i < n; i++ {
+			if !sameWord(p, otherElems[i], elems[i]) {
+				n = i
+				break
+			}
+		}
+		if d := leadingDotDots(p, otherElems); d > dotdots {
+			dotdots = d
+		}
+	}
+	if n < dotdots {
+		// At least one path is outside of the common prefix we found, and
+		// so the ancestor must be as far up as the one furthest outside.
+		n = dotdots
+		elems = make([]string, n)
+		for i := range elems {
+			elems[i] = ".."
+		}
+	}
+
+	ret := vol
+	if rooted && len(vol) <= 2 {
+		// A UNC volume name is always rooted, so doesn't need a separator.
+		ret += string(syntaxOf(p).separator())
+	}
+	if isObjectKey(p) {
+		return joinComponents(p, elems[:n]), true
+	}
+	if n == 0 {
+		return p.Clean(ret), true
+	}
+	return p.Join(append([]string{ret}, elems[:n]...)...), true
+}
+
+// leadingDotDots returns the number of ".." components at the start of the
+// given cleaned components, which is always zero for ObjectKey.
+func leadingDotDots(p P, elems []string) int {
+	if isObjectKey(p) {
+		return 0
+	}
+	n := 0
+	for n < len(elems) && elems[n] == ".." {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,81 @@
+package paths
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommonAncestor(t *testing.T) {
+	type Test struct {
+		paths []string
+		want  string
+	}
+
+	implTests := map[string][]Test{
+		"Unix": {
+			{[]string{"/srv/out/a/x.o", "/srv/out/b"}, "/srv/out"},
+			{[]string{"/srv/out/a", "/srv/out/a/b/c", "/srv/out/a/b"}, "/srv/out/a"},
+			{[]string{"/srv/outer", "/srv/out"}, "/srv"},
+			{[]string{"/srv/Out/a", "/srv/out/b"}, "/srv"},
+			{[]string{"/a", "/b"}, "/"},
+			{[]string{"//srv/./out/", "/srv/out/x"}, "/srv/out"},
+			{[]string{"/srv/out"}, "/srv/out"},
+			{[]string{"a/b", "a/c"}, "a"},
+			{[]string{"a", "b"}, "."},
+			{[]string{"../a", "b"}, ".."},
+			{[]string{"../a", "../../b", "c"}, "../.."},
+			{[]string{"../a/x", "../a/y"}, "../a"},
+			{[]string{"/a", "a"}, "err"},
+			{nil, "err"},
+		},
+		"Windows": {
+			{[]string{`C:\Build\a`, `c:/build/b`}, `C:\Build`},
+			{[]string{`c:\build\a`, `C:\BUILD\b`}, `c:\build`},
+			{[]string{`C:\a`, `C:\b`}, `C:\`},
+			{[]string{`C:\a`, `D:\a`}, "err"},
+			{[]string{`C:\a`, `\a`}, "err"},
+			{[]string{`C:\a`, `C:a`}, "err"},
+			{[]string{`C:a\x`, `c:a\y`}, `C:a`},
+			{[]string{`\\server\share\a\x`, `\\SERVER\share\a\y`}, `\\server\share\a`},
+			{[]string{`\\server\share\a`, `\\server\share\b`}, `\\server\share`},
+			{[]string{`\\server\share\a`, `\\server\other\a`}, "err"},
+			{[]string{`a\b`, `a/c`}, `a`},
+		},
+		"ObjectKey": {
+			{[]string{"a/b/x", "a/b/y"}, "a/b"},
+			{[]string{"a//x", "a//y"}, "a/"},
+			{[]string{"a//x", "a/x"}, "a"},
+			{[]string{"../a", "../b"}, ".."},
+			{[]string{"a", "b"}, ""},
+			{[]string{"a:b/x", "a:b/y"}, "a:b"},
+		},
+	}
+	impls := map[string]P{
+		"Unix":      Unix,
+		"Windows":   Windows,
+		"ObjectKey": ObjectKey{},
+	}
+
+	for implName, tests := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			for _, test := range tests {
+				t.Run(strings.Join(test.paths, " "), func(t *testing.T) {
+					got, ok := CommonAncestor(p, test.paths...)
+					if test.want == "err" {
+						if ok {
+							t.Errorf("wrong result for CommonAncestor(%q)\ngot:  %s\nwant: no ancestor", test.paths, got)
+						}
+						return
+					}
+					if !ok {
+						t.Fatalf("no ancestor for CommonAncestor(%q)", test.paths)
+					}
+					if got != test.want {
+						t.Errorf("wrong result for CommonAncestor(%q)\ngot:  %s\nwant: %s", test.paths, got, test.want)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrAbsoluteEntry is the error wrapped by an ArchiveEntryError for an
+	// entry whose name is an absolute path or begins with a drive letter.
+	ErrAbsoluteEntry = errors.New("absolute path not allowed")
+
+	// ErrEntryTraversal is the error wrapped by an ArchiveEntryError for an
+	// entry whose name uses ".." components to refer to a file outside of
+	// the destination directory.
+	ErrEntryTraversal = errors.New("path refers to a file outside of the destination directory")
+
+	// ErrInvalidEntryName is the error wrapped by an ArchiveEntryError for an
+	// entry whose name includes a component that cannot be used as a file
+	// name on the target system, such as "aux.c" or "a<b" on Windows.
+	ErrInvalidEntryName = errors.New("name not allowed on the target system")
+
+	// ErrEntryCollision is the error wrapped by an ArchiveEntryError for an
+	// entry whose native path would refer to the same file as an earlier
+	// entry with a differently-spelled name, such as "README" and "readme"
+	// on Windows.
+	ErrEntryCollision = errors.New("name collides with another entry")
+)
+
+// ArchiveEntryError is the type of error returned for an archive entry name
+// that cannot be safely extracted. It wraps one of ErrAbsoluteEntry,
+// ErrEntryTraversal, ErrInvalidEntryName or ErrEntryCollision.
+type ArchiveEntryError struct {
+	// Name is the entry name as it appears in the archive.
+	Name string
+
+	// Other is the name of the earlier entry that Name collides with, set
+	// only if Err is ErrEntryCollision.
+	Other string
+
+	Err error
+}
+
+func (e *ArchiveEntryError) Error() string {
+	if e.Other != "" {
+		return fmt.Sprintf("archive entry %q: %s %q", e.Name, e.Err, e.Other)
+	}
+	return fmt.Sprintf("archive entry %q: %s", e.Name, e.Err)
+}
+
+func (e *ArchiveEntryError) Unwrap() error {
+	return e.Err
+}
+
+// ArchiveEntryPath returns the native path that an archive entry with the
+// given name should be extracted to, under the native destination directory
+// dest, using the given path implementation.
+//
+// The name is the slash-separated name of an entry in a tar or zip archive,
+// as in tar.Header.Name or zip.File.Name. Because archivers on Windows
+// sometimes write backslashes instead of slashes, backslashes are also
+// treated as separators, regardless of the path implementation.
+//
+// A name beginning with a drive letter, like "C:/x" or "C:x", is absolute
+// for Windows, but for other implementations its first component is just a
+// file name containing a colon.
+//
+// The result is an error of type *ArchiveEntryError if the name is absolute,
+// if it would refer to a file outside of dest, or if any of its components
+// cannot be used as a file name on the target system. A name that refers to
+// dest itself, such as "./", produces dest.
+func ArchiveEntryPath(p P, dest, name string) (string, error) {
+	elems, err := archiveEntryElems(p, name)
+	if err != nil {
+		return "", err
+	}
+	return p.Join(append([]string{dest}, elems...)...), nil
+}
+
+// archiveEntryElems returns the cleaned components of the given archive entry
+// name, as validated by ArchiveEntryPath.
+func archiveEntryElems(p P, name string) ([]string, error) {
+	slashed := strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(slashed, "/") || (syntaxOf(p) == windowsImpl && Windows.VolumeName(slashed) != "") {
+		return nil, &ArchiveEntryError{Name: name, Err: ErrAbsoluteEntry}
+	}
+	cleaned := path.Clean(slashed)
+	if cleaned == "." {
+		return nil, nil
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return nil, &ArchiveEntryError{Name: name, Err: ErrEntryTraversal}
+	}
+	elems := strings.Split(cleaned, "/")
+	for _, elem := range elems {
+		if !validFileName(p, elem) {
+			return nil, &ArchiveEntryError{Name: name, Err: ErrInvalidEntryName}
+		}
+	}
+	return elems, nil
+}
+
+// ArchiveDest maps the names of the entries of a single archive to native
+// paths under a destination directory, as with ArchiveEntryPath, while also
+// checking that no two entries collide.
+//
+// Two entries collide if they refer to the same file, or to files in the
+// same directory, but spell the name of that file or directory differently,
+// such as "Docs/README" and "docs/readme" with the Windows implementation.
+// Extracting both such entries would silently merge or overwrite files, and
+// the result would depend on the order of the entries. Entries with names
+// that are spelled identically do not collide, since archives may contain
+// multiple entries for the same file.
+//
+// Use NewArchiveDest to create an ArchiveDest.
+type ArchiveDest struct {
+	p    P
+	dest string
+
+	// seen maps the wordKey of each entry name and each of its parent
+	// directories, in slash form, to the spelling first seen for that key
+	// and the name of the entry where it was seen.
+	seen map[string][2]string
+}
+
+// NewArchiveDest returns a new ArchiveDest for extracting an archive to the
+// given native destination directory.
+func NewArchiveDest(p P, dest string) *ArchiveDest {
+	return &ArchiveDest{
+		p:    p,
+		dest: dest,
+		seen: make(map[string][2]string),
+	}
+}
+
+// Path returns the native path that the archive entry with the given name
+// should be extracted to. The result is an error of type *ArchiveEntryError
+// for any of the reasons described for ArchiveEntryPath, or if the entry
+// collides with an earlier entry passed to the same ArchiveDest.
+func (d *ArchiveDest) Path(name string) (string, error) {
+	elems, err := archiveEntryElems(d.p, name)
+	if err != nil {
+		return "", err
+	}
+	for i := range elems {
+		spelling := strings.Join(elems[:i+1], "/")
+		key := wordKey(d.p, spelling)
+		if prev, ok := d.seen[key]; ok {
+			if prev[0] != spelling {
+				return "", &ArchiveEntryError{Name: name, Other: prev[1], Err: ErrEntryCollision}
+			}
+			continue
+		}
+		d.seen[key] = [2]string{spelling, name}
+	}
+	return d.p.Join(append([]string{d.dest}, elems...)...), nil
+}
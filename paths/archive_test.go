@@ -0,0 +1,151 @@
+package paths
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestArchiveEntryPath(t *testing.T) {
+	type Test struct {
+		name string
+		want string
+	}
+
+	implTests := map[string]struct {
+		dest  string
+		tests []Test
+	}{
+		"Unix": {
+			"/srv/out",
+			[]Test{
+				{"a.txt", "/srv/out/a.txt"},
+				{"dir/", "/srv/out/dir"},
+				{"dir/sub/file", "/srv/out/dir/sub/file"},
+				{`dir\sub\file`, "/srv/out/dir/sub/file"},
+				{"./dir//./file", "/srv/out/dir/file"},
+				{"dir/../file", "/srv/out/file"},
+				{"./", "/srv/out"},
+				{"CON", "/srv/out/CON"},
+				{"ab:c", "/srv/out/ab:c"},
+				{"/etc/passwd", "abs"},
+				{`\etc\passwd`, "abs"},
+				{"C:/x", "/srv/out/C:/x"},
+				{"C:foo", "/srv/out/C:foo"},
+				{"../x", "traversal"},
+				{"dir/../../x", "traversal"},
+				{`..\x`, "traversal"},
+				{"..", "traversal"},
+				{"a\x00b", "invalid"},
+			},
+		},
+		"Windows": {
+			`C:\out`,
+			[]Test{
+				{"a.txt", `C:\out\a.txt`},
+				{"dir/sub/file", `C:\out\dir\sub\file`},
+				{`dir\sub\file`, `C:\out\dir\sub\file`},
+				{"dir/../file", `C:\out\file`},
+				{"./", `C:\out`},
+				{"C:/Windows/system32", "abs"},
+				{"c:x", "abs"},
+				{"//server/share/x", "abs"},
+				{`\\?\C:\x`, "abs"},
+				{"../x", "traversal"},
+				{`dir\..\..\x`, "traversal"},
+				{"dir/CON", "invalid"},
+				{"aux.c", "invalid"},
+				{"com1.txt/x", "invalid"},
+				{"ab:c", "invalid"},
+				{"what?", "invalid"},
+				{"trailing./x", "invalid"},
+				{"trailing ", "invalid"},
+			},
+		},
+	}
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Windows": Windows,
+	}
+	wantErrs := map[string]error{
+		"abs":       ErrAbsoluteEntry,
+		"traversal": ErrEntryTraversal,
+		"invalid":   ErrInvalidEntryName,
+	}
+
+	for implName, implTest := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			for _, test := range implTest.tests {
+				t.Run(test.name, func(t *testing.T) {
+					got, err := ArchiveEntryPath(p, implTest.dest, test.name)
+					if wantErr, ok := wantErrs[test.want]; ok {
+						var entryErr *ArchiveEntryError
+						if !errors.As(err, &entryErr) || entryErr.Name != test.name {
+							t.Fatalf("wrong error for ArchiveEntryPath(%q)\ngot:  %v\nwant: an ArchiveEntryError", test.name, err)
+						}
+						if !errors.Is(err, wantErr) {
+							t.Errorf("wrong error for ArchiveEntryPath(%q)\ngot:  %v\nwant: %v", test.name, err, wantErr)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error for ArchiveEntryPath(%q): %s", test.name, err)
+					}
+					if got != test.want {
+						t.Errorf("wrong result for ArchiveEntryPath(%q)\ngot:  %s\nwant: %s", test.name, got, test.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestArchiveDest(t *testing.T) {
+	names := []string{
+		"Docs/",
+		"Docs/README",
+		"Docs/README",
+		"docs/other",
+		"Docs/readme",
+		"src/main.go",
+		"SRC",
+		"../escape",
+	}
+	implTests := map[string][]string{
+		"Unix": {
+			"", "", "", "", "", "", "", "traversal",
+		},
+		"Windows": {
+			"", "", "", "Docs/", "Docs/README", "", "src/main.go", "traversal",
+		},
+	}
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Windows": Windows,
+	}
+
+	for implName, wants := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			d := NewArchiveDest(impls[implName], "out")
+			for i, name := range names {
+				_, err := d.Path(name)
+				want := wants[i]
+				var entryErr *ArchiveEntryError
+				switch {
+				case want == "":
+					if err != nil {
+						t.Errorf("unexpected error for Path(%q): %s", name, err)
+					}
+				case want == "traversal":
+					if !errors.Is(err, ErrEntryTraversal) {
+						t.Errorf("wrong error for Path(%q)\ngot:  %v\nwant: %v", name, err, ErrEntryTraversal)
+					}
+				case !errors.As(err, &entryErr) || !errors.Is(err, ErrEntryCollision):
+					t.Errorf("wrong error for Path(%q)\ngot:  %v\nwant: %v", name, err, ErrEntryCollision)
+				case entryErr.Other != want:
+					t.Errorf("wrong collision for Path(%q)\ngot:  %s\nwant: %s", name, entryErr.Other, want)
+				}
+			}
+		})
+	}
+}
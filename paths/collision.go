@@ -0,0 +1,98 @@
+package paths
+
+import (
+	"strings"
+)
+
+// Collision describes a group of paths that would refer to the same file
+// on the target system of a path implementation, as returned by
+// FindCollisions.
+type Collision struct {
+	// Paths are the colliding paths, as given to FindCollisions and in the
+	// same order.
+	Paths []string
+
+	// Device is the reserved device name, such as "AUX", that the paths
+	// refer to if they collide with a Windows device rather than with each
+	// other. In that case Paths may have only one element, since a file
+	// with that name can't be created at all.
+	Device string
+}
+
+// FindCollisions returns the groups of paths from the given list that are
+// spelled differently but would refer to the same file, or to files in the
+// same directory, on the target system of the given path implementation.
+// For example, "README.md" and "readme.md" collide for Windows and Darwin
+// but not for Unix, as do "Docs/a.md" and "docs/b.md" because they would
+// be written to the same directory.
+//
+// Paths are compared by their cleaned components, using the case rules of
+// the path implementation, and so Darwin also detects paths that differ
+// only in Unicode normalization form. Windows also detects paths that differ
+// only by trailing dots or spaces, which Windows removes from file names,
+// and paths that refer to reserved devices such as "aux.c", which are
+// grouped by device.
+//
+// Paths that are spelled identically after cleaning do not collide with
+// each other. Each colliding path appears in only one group, the one for its
+// shortest colliding prefix, and the groups are in the order that their first
+// paths appear in the list.
+func FindCollisions(p P, paths []string) []Collision {
+	im := syntaxOf(p)
+
+	// First we find all of the spellings of each prefix of each path, and
+	// keep the keys of those prefixes for the second pass below.
+	spellings := make(map[string]map[string]struct{})
+	keys := make([][]string, len(paths))
+	for i, path := range paths {
+		vol, rooted, elems := splitComponents(p, path)
+		// Volume names are not file names, and so differently-spelled
+		// volume names are not a collision.
+		key := wordKey(p, vol)
+		if rooted {
+			key += string(im.separator())
+		}
+		spelling := key
+		for _, elem := range elems {
+			keyElem := elem
+			if im == windowsImpl {
+				if device := windowsLegacyDeviceName(elem); device != "" {
+					keys[i] = append(keys[i], "device:"+strings.ToUpper(device))
+					break
+				}
+				keyElem = strings.TrimRight(elem, ". ")
+			}
+			spelling += elem + string(im.separator())
+			key += wordKey(p, keyElem) + string(im.separator())
+			if spellings[key] == nil {
+				spellings[key] = make(map[string]struct{})
+			}
+			spellings[key][spelling] = struct{}{}
+			keys[i] = append(keys[i], key)
+		}
+	}
+
+	// Then we can assign each path to the group for its shortest prefix
+	// that has more than one spelling.
+	var ret []Collision
+	groups := make(map[string]int)
+	for i, path := range paths {
+		for _, key := range keys[i] {
+			device := strings.TrimPrefix(key, "device:")
+			if device == key {
+				device = ""
+				if len(spellings[key]) < 2 {
+					continue
+				}
+			}
+			if g, ok := groups[key]; ok {
+				ret[g].Paths = append(ret[g].Paths, path)
+			} else {
+				groups[key] = len(ret)
+				ret = append(ret, Collision{Paths: []string{path}, Device: device})
+			}
+			break
+		}
+	}
+	return ret
+}
@@ -0,0 +1,69 @@
+package paths
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindCollisions(t *testing.T) {
+	paths := []string{
+		"README.md",
+		"src/main.go",
+		"readme.md",
+		"Docs/a.md",
+		"docs/b.md",
+		"docs/B.md",
+		"caf\u00e9.txt",
+		"cafe\u0301.txt",
+		"lib/aux.c",
+		"src/AUX.h",
+		"./src//main.go",
+		"notes.",
+		"notes",
+		"/abs/file",
+		"/ABS/file",
+		"abs/file",
+	}
+
+	tests := map[string][]Collision{
+		"Unix": nil,
+		"Darwin": {
+			{Paths: []string{"README.md", "readme.md"}},
+			{Paths: []string{"Docs/a.md", "docs/b.md", "docs/B.md"}},
+			{Paths: []string{"caf\u00e9.txt", "cafe\u0301.txt"}},
+			{Paths: []string{"/abs/file", "/ABS/file"}},
+		},
+		"Windows": {
+			{Paths: []string{"README.md", "readme.md"}},
+			{Paths: []string{"Docs/a.md", "docs/b.md", "docs/B.md"}},
+			{Paths: []string{"lib/aux.c", "src/AUX.h"}, Device: "AUX"},
+			{Paths: []string{"notes.", "notes"}},
+			{Paths: []string{"/abs/file", "/ABS/file"}},
+		},
+	}
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Darwin":  Darwin,
+		"Windows": Windows,
+	}
+
+	for implName, want := range tests {
+		t.Run(implName, func(t *testing.T) {
+			got := FindCollisions(impls[implName], paths)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+
+	t.Run("Windows volumes", func(t *testing.T) {
+		got := FindCollisions(Windows, []string{`C:\a`, `c:/a`, `D:\a`, `\\server\share\x`, `\\SERVER\share\X`, `C:\nul`})
+		want := []Collision{
+			{Paths: []string{`\\server\share\x`, `\\SERVER\share\X`}},
+			{Paths: []string{`C:\nul`}, Device: "NUL"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
+}
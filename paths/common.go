@@ -1,17 +1,21 @@
 package paths
 
 import (
-	"errors"
 	"net/url"
 	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // impl is am implementation of P that can either support Unix or Windows
-// semantics, with many behaviors common to both.
+// semantics, with many behaviors common to both. Darwin is a variant of Unix
+// that differs only in how it compares path components.
 type impl uint8
 
 const windowsImpl impl = '\\'
 const unixImpl impl = '/'
+const darwinImpl impl = 'D'
 
 func (im impl) Base(path string) string {
 	if path == "" {
@@ -186,7 +190,7 @@ func (im impl) Rel(basepath, targpath string) (string, error) {
 	baseSlashed := len(base) > 0 && base[0] == im.separator()
 	targSlashed := len(targ) > 0 && targ[0] == im.separator()
 	if baseSlashed != targSlashed || !im.sameWord(baseVol, targVol) {
-		return "", errors.New("can't make " + targpath + " relative to " + basepath)
+		return "", &RelError{Base: basepath, Target: targpath}
 	}
 	// Position base[b0:bi] and targ[t0:ti] at the first differing elements.
 	bl := len(base)
@@ -212,7 +216,7 @@ func (im impl) Rel(basepath, targpath string) (string, error) {
 		t0 = ti
 	}
 	if base[b0:bi] == ".." {
-		return "", errors.New("can't make " + targpath + " relative to " + basepath)
+		return "", &RelError{Base: basepath, Target: targpath}
 	}
 	if b0 != bl {
 		// Base elements left. Must go up before going down.
@@ -310,12 +314,26 @@ func (im impl) volumeNameLen(path string) int {
 }
 
 func (im impl) separator() uint8 {
-	return uint8(im)
+	switch im {
+	case windowsImpl:
+		return '\\'
+	default:
+		return '/'
+	}
+}
+
+func (im impl) listSeparator() uint8 {
+	switch im {
+	case windowsImpl:
+		return ';'
+	default:
+		return ':'
+	}
 }
 
 func (im impl) isPathSeparator(s uint8) bool {
 	switch {
-	case s == uint8(im):
+	case s == im.separator():
 		return true
 	case im == windowsImpl && s == '/':
 		return true
@@ -325,30 +343,184 @@ func (im impl) isPathSeparator(s uint8) bool {
 }
 
 func (im impl) fromSlash(path string) string {
-	if im == unixImpl {
+	if im != windowsImpl {
 		return path
 	}
-	return strings.Replace(path, "/", string(im), -1)
+	return strings.Replace(path, "/", string(im.separator()), -1)
 }
 
 func (im impl) toSlash(path string) string {
-	if im == unixImpl {
+	if im != windowsImpl {
 		return path
 	}
-	return strings.Replace(path, string(im), "/", -1)
+	return strings.Replace(path, string(im.separator()), "/", -1)
 }
 
 func (im impl) sameWord(a, b string) bool {
 	switch im {
 	case windowsImpl:
 		return strings.EqualFold(a, b)
+	case darwinImpl:
+		return strings.EqualFold(norm.NFD.String(a), norm.NFD.String(b))
 	default:
 		return a == b
 	}
 }
 
+// wordKey returns a string that is equal for any two path components that
+// sameWord considers to be equal, for use as a map key.
+func (im impl) wordKey(s string) string {
+	switch im {
+	case windowsImpl:
+		return foldKey(s)
+	case darwinImpl:
+		return foldKey(norm.NFD.String(s))
+	default:
+		return s
+	}
+}
+
+// foldKey returns a string that is equal for any two strings that
+// strings.EqualFold considers to be equal, by replacing each character with
+// the lowest-numbered character that it is equal to under simple case folding.
+func foldKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		min := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < min {
+				min = f
+			}
+		}
+		return min
+	}, s)
+}
+
 // isSlash returns true if the given character is either a slash or a backslash,
 // regardless of platform. For a platform-specific answer, use im.isPathSeparator.
 func isSlash(c uint8) bool {
 	return c == '\\' || c == '/'
 }
+
+// syntaxOf returns the impl whose path syntax the given P follows, for use by
+// functions that accept any P but must choose between Windows and Unix
+// conventions. Implementations other than Windows are assumed to follow
+// Unix conventions.
+func syntaxOf(p P) impl {
+	if im, ok := p.(impl); ok {
+		return im
+	}
+	return unixImpl
+}
+
+// sameWord compares two path components using the case rules of the given
+// path implementation.
+func sameWord(p P, a, b string) bool {
+	return syntaxOf(p).sameWord(a, b)
+}
+
+// wordKey returns the map key for a path component using the case rules of
+// the given path implementation.
+func wordKey(p P, s string) string {
+	return syntaxOf(p).wordKey(s)
+}
+
+// splitComponents cleans the given path and then splits it into its volume
+// name, a flag indicating whether it is rooted, and its remaining components.
+// A path with a UNC volume name is always rooted, even if nothing follows
+// the volume name. The current directory "." has no components at all.
+//
+// An ObjectKey is instead split at every delimiter, since "//", "." and ".."
+// have no special meaning in a key, and only the empty key has no components.
+func splitComponents(p P, path string) (vol string, rooted bool, elems []string) {
+	if k, ok := p.(ObjectKey); ok {
+		if path == "" {
+			return "", false, nil
+		}
+		return "", false, strings.Split(path, k.delimiter())
+	}
+	sep := syntaxOf(p).separator()
+	path = p.Clean(path)
+	vol = p.VolumeName(path)
+	rest := path[len(vol):]
+	rooted = len(vol) > 2
+	if rest != "" && rest[0] == sep {
+		rooted = true
+		rest = rest[1:]
+	}
+	if rest == "" || rest == "." {
+		return vol, rooted, nil
+	}
+	return vol, rooted, strings.Split(rest, string(sep))
+}
+
+// trimComponents removes the given prefix from the given path if every
+// component of the prefix matches the corresponding leading component of
+// the path, returning the remaining components of the path. The second
+// return value is false if the prefix does not match, including if the path
+// would be outside of a relative prefix, like "../a" with the prefix ".".
+func trimComponents(p P, path, prefix string) ([]string, bool) {
+	pathVol, pathRooted, pathElems := splitComponents(p, path)
+	prefixVol, prefixRooted, prefixElems := splitComponents(p, prefix)
+	if pathRooted != prefixRooted || !sameWord(p, pathVol, prefixVol) {
+		return nil, false
+	}
+	if len(prefixElems) > len(pathElems) {
+		return nil, false
+	}
+	for i, elem := range prefixElems {
+		if !sameWord(p, pathElems[i], elem) {
+			return nil, false
+		}
+	}
+	rest := pathElems[len(prefixElems):]
+	if len(rest) != 0 && rest[0] == ".." && !isObjectKey(p) {
+		// A cleaned path only has ".." components at the start, and so
+		// this can only happen for a relative prefix with fewer of them.
+		return nil, false
+	}
+	return rest, true
+}
+
+// joinComponents is the inverse of splitComponents for the components of a
+// relative path, returning "." if there are none. For ObjectKey it joins
+// the components with the delimiter exactly, and returns the empty key if
+// there are none.
+func joinComponents(p P, elems []string) string {
+	if k, ok := p.(ObjectKey); ok {
+		return strings.Join(elems, k.delimiter())
+	}
+	if len(elems) == 0 {
+		return "."
+	}
+	return p.Join(elems...)
+}
+
+// isObjectKey returns true if the given path implementation is an ObjectKey,
+// whose keys have no special components.
+func isObjectKey(p P) bool {
+	_, ok := p.(ObjectKey)
+	return ok
+}
+
+// validFileName returns true if the given string can be used as the name of
+// a new file on the target system of the given path implementation. On
+// Windows this excludes names containing characters that Windows does not
+// allow, names ending with a dot or a space, and reserved device names such
+// as "CON" or "aux.c".
+func validFileName(p P, name string) bool {
+	if name == "" || name == "." || name == ".." || strings.IndexByte(name, 0) >= 0 {
+		return false
+	}
+	if syntaxOf(p) != windowsImpl {
+		return strings.IndexByte(name, '/') < 0
+	}
+	for _, c := range []byte(name) {
+		if c < ' ' || strings.IndexByte(`<>:"/\|?*`, c) >= 0 {
+			return false
+		}
+	}
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return false
+	}
+	return windowsLegacyDeviceName(name) == ""
+}
@@ -627,3 +627,29 @@ func TestVolumeName(t *testing.T) {
 		})
 	}
 }
+
+func TestToURL(t *testing.T) {
+	tests := []struct {
+		p         P
+		path, url string
+	}{
+		{Unix, "/foo/bar", "file:///foo/bar"},
+		{Unix, "foo/bar", "foo/bar"},
+		{Windows, `c:\foo\bar`, "file:///c:/foo/bar"},
+		{Windows, `foo\bar`, "foo/bar"},
+		{Windows, `\\host\share\foo\bar`, "file://host/share/foo/bar"},
+		{Windows, `//host/share/foo/../bar`, "file://host/share/bar"},
+		{Windows, `C:\foo\..\bar\.\baz`, "file:///C:/bar/baz"},
+		{Windows, `C:/foo//bar/`, "file:///C:/foo/bar"},
+		{Windows, `.\foo\..\bar`, "bar"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got := test.p.ToURL(test.path).String()
+			if got != test.url {
+				t.Errorf("wrong result for ToURL(%q)\ngot:  %s\nwant: %s", test.path, got, test.url)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package paths
+
+// Darwin is a P implementation that consumes and generates paths suitable for
+// macOS and other Darwin-based systems.
+//
+// Darwin paths have the same syntax as Unix paths and so the two produce
+// the same results for most methods, but Darwin compares path components
+// in the same way as the default case-insensitive configurations of the
+// APFS and HFS+ filesystems: ignoring differences of letter case and of
+// Unicode normalization form. For example, Darwin.Rel considers "/Users"
+// and "/users" to be the same directory. This affects any function in this
+// package that compares path components.
+//
+// The Target variable and ForGOOS function select Unix rather than Darwin on
+// Darwin-based systems, because macOS filesystems can be configured to be
+// case-sensitive.
+var Darwin P
+
+func init() {
+	Darwin = darwinImpl
+}
@@ -28,6 +28,9 @@
 // The variable Slash is a wrapper around the "path" package for handling
 // slash-based paths as seen in URLs.
 //
+// For object store keys, where "//" and ".." are literal parts of a key
+// rather than syntax to be cleaned away, use an ObjectKey value instead.
+//
 // Other Implementations
 //
 // Interface P is the type of all of the different path implementations in this
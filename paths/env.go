@@ -0,0 +1,236 @@
+package paths
+
+import (
+	"strings"
+)
+
+// EnvLookup is the type of a function that returns the value of the
+// environment variable with the given name on some target system, along with
+// a flag that is true only if the variable is set.
+//
+// The environment of a remote system is not available through package os,
+// so callers of ExpandEnv must provide their own lookup function. EnvMap
+// returns a lookup function for variables already gathered into a map.
+type EnvLookup func(name string) (value string, ok bool)
+
+// EnvMap returns an EnvLookup that reads variables from the given map, using
+// the variable naming rules of the given path implementation.
+//
+// Environment variable names are case-insensitive on Windows, so the lookup
+// function for Windows will find "ProgramFiles" in a map containing the key
+// "PROGRAMFILES", preferring an exact match if there is one. If there is no
+// exact match but several keys differ from the name only by case, it uses
+// the key that sorts first. For all other implementations names must match
+// exactly.
+func EnvMap(p P, env map[string]string) EnvLookup {
+	if syntaxOf(p) != windowsImpl {
+		return func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		}
+	}
+	return func(name string) (string, bool) {
+		if v, ok := env[name]; ok {
+			return v, true
+		}
+		// If more than one key differs from the name only by case, the
+		// one that sorts first wins, so that the result doesn't depend
+		// on the map's iteration order.
+		var match string
+		found := false
+		for k := range env {
+			if strings.EqualFold(k, name) && (!found || k < match) {
+				match, found = k, true
+			}
+		}
+		return env[match], found
+	}
+}
+
+// ExpandEnv replaces references to environment variables in the given string
+// using the syntax of the target system of the given path implementation,
+// calling the given function to find the value of each variable.
+//
+// For Windows, variables are written as %NAME%, as understood by the
+// ExpandEnvironmentStrings function. References to variables that are not
+// set are left unchanged, including their percent signs.
+//
+// For all other implementations, variables use a subset of the POSIX shell
+// syntax: $NAME, ${NAME}, and the forms ${NAME:-default}, ${NAME-default},
+// ${NAME:+alternate} and ${NAME+alternate}, whose default or alternate words
+// are themselves expanded. References to variables that are not set expand
+// to the empty string, as in the shell. A dollar sign not followed by a
+// valid variable name is retained literally.
+func ExpandEnv(p P, s string, lookup EnvLookup) string {
+	switch syntaxOf(p) {
+	case windowsImpl:
+		return expandWindowsEnv(s, '%', lookup)
+	default:
+		return expandUnixEnv(s, lookup)
+	}
+}
+
+// ExpandEnvDelayed is like ExpandEnv except that for Windows it additionally
+// expands variables written as !NAME!, as done by the Windows command
+// interpreter when delayed expansion is enabled.
+//
+// As in the command interpreter, the %NAME% references are expanded first
+// and then any !NAME! references in the result are expanded. References to
+// variables that are not set are left unchanged.
+//
+// Delayed expansion is a Windows concept, so for other implementations this
+// function is equivalent to ExpandEnv.
+func ExpandEnvDelayed(p P, s string, lookup EnvLookup) string {
+	switch syntaxOf(p) {
+	case windowsImpl:
+		s = expandWindowsEnv(s, '%', lookup)
+		return expandWindowsEnv(s, '!', lookup)
+	default:
+		return expandUnixEnv(s, lookup)
+	}
+}
+
+// expandWindowsEnv replaces variable references delimited on both sides by
+// the given delimiter character, which is either '%' or '!'.
+func expandWindowsEnv(s string, delim byte, lookup EnvLookup) string {
+	var buf strings.Builder
+	for {
+		start := strings.IndexByte(s, delim)
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start+1:], delim)
+		if end < 0 {
+			break
+		}
+		end += start + 1
+		buf.WriteString(s[:start])
+		name := s[start+1 : end]
+		if name == "" {
+			// Two adjacent delimiters are retained literally, but the
+			// second one might begin a new reference so we mustn't
+			// consume it yet.
+			buf.WriteByte(delim)
+			s = s[end:]
+			continue
+		}
+		if v, ok := lookup(name); ok {
+			buf.WriteString(v)
+		} else {
+			// Unset variables are left as-is.
+			buf.WriteString(s[start : end+1])
+		}
+		s = s[end+1:]
+	}
+	buf.WriteString(s)
+	return buf.String()
+}
+
+func expandUnixEnv(s string, lookup EnvLookup) string {
+	var buf strings.Builder
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i == len(s)-1 {
+			break
+		}
+		buf.WriteString(s[:i])
+		s = s[i+1:]
+
+		if s[0] == '{' {
+			end := matchingBrace(s)
+			if end < 0 {
+				// Unterminated, so we'll treat it as literal
+				buf.WriteByte('$')
+				continue
+			}
+			buf.WriteString(expandUnixBraced(s[1:end], lookup))
+			s = s[end+1:]
+			continue
+		}
+
+		n := unixEnvNameLen(s)
+		if n == 0 {
+			buf.WriteByte('$')
+			continue
+		}
+		v, _ := lookup(s[:n])
+		buf.WriteString(v)
+		s = s[n:]
+	}
+	buf.WriteString(s)
+	return buf.String()
+}
+
+// expandUnixBraced expands the content between the braces of a ${...}
+// variable reference.
+func expandUnixBraced(expr string, lookup EnvLookup) string {
+	n := unixEnvNameLen(expr)
+	if n == 0 {
+		// Not a valid reference, so we'll retain it literally.
+		return "${" + expr + "}"
+	}
+	name, op := expr[:n], expr[n:]
+	v, set := lookup(name)
+	if op == "" {
+		return v
+	}
+
+	colon := false
+	if op[0] == ':' {
+		colon = true
+		op = op[1:]
+	}
+	if op == "" {
+		return "${" + expr + "}"
+	}
+	word := op[1:]
+	// With a colon, an empty value is treated as if it were unset.
+	present := set && !(colon && v == "")
+	switch op[0] {
+	case '-':
+		if !present {
+			return expandUnixEnv(word, lookup)
+		}
+		return v
+	case '+':
+		if present {
+			return expandUnixEnv(word, lookup)
+		}
+		return ""
+	default:
+		return "${" + expr + "}"
+	}
+}
+
+// matchingBrace returns the index of the brace that closes the one at
+// the start of the given string, or -1 if it is not closed.
+func matchingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// unixEnvNameLen returns the length of the valid shell variable name at the
+// start of the given string, or zero if it does not start with a name.
+func unixEnvNameLen(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return i
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,142 @@
+package paths
+
+import (
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	type Test struct {
+		s, want string
+	}
+
+	env := map[string]string{
+		"HOME":          "/home/alice",
+		"XDG_DATA_HOME": "/home/alice/.local/share",
+		"EMPTY":         "",
+		"ProgramFiles":  `C:\Program Files`,
+		"APPDATA":       `C:\Users\alice\AppData\Roaming`,
+		"BANG":          "!APPDATA!",
+	}
+
+	implTests := map[string][]Test{
+		"Unix": {
+			{"", ""},
+			{"/usr/bin", "/usr/bin"},
+			{"$HOME/app", "/home/alice/app"},
+			{"${HOME}/app", "/home/alice/app"},
+			{"${XDG_DATA_HOME}/app", "/home/alice/.local/share/app"},
+			{"$HOMEX/app", "/app"},
+			{"${HOME}X/app", "/home/aliceX/app"},
+			{"$UNSET/app", "/app"},
+			{"${UNSET:-/opt}/app", "/opt/app"},
+			{"${EMPTY:-/opt}/app", "/opt/app"},
+			{"${EMPTY-/opt}/app", "/app"},
+			{"${UNSET-/opt}/app", "/opt/app"},
+			{"${HOME:-/opt}/app", "/home/alice/app"},
+			{"${XDG_CONFIG_HOME:-$HOME/.config}/app", "/home/alice/.config/app"},
+			{"${XDG_CONFIG_HOME:-${HOME}/.config}/app", "/home/alice/.config/app"},
+			{"${HOME:+set}", "set"},
+			{"${EMPTY:+set}", ""},
+			{"${EMPTY+set}", "set"},
+			{"${UNSET+set}", ""},
+			{"a$", "a$"},
+			{"a$/b", "a$/b"},
+			{"$1", "$1"},
+			{"${HOME", "${HOME"},
+			{"${}", "${}"},
+			{"${HOME:}", "${HOME:}"},
+			{"${HOME?x}", "${HOME?x}"},
+			{"%HOME%", "%HOME%"},
+		},
+		"Windows": {
+			{"", ""},
+			{`C:\Windows`, `C:\Windows`},
+			{`%ProgramFiles%\App`, `C:\Program Files\App`},
+			{`%PROGRAMFILES%\App`, `C:\Program Files\App`},
+			{`%programfiles%\App`, `C:\Program Files\App`},
+			{`%APPDATA%\%ProgramFiles%`, `C:\Users\alice\AppData\Roaming\C:\Program Files`},
+			{`%UNSET%\App`, `%UNSET%\App`},
+			{`%UNSET%APPDATA%`, `%UNSET%APPDATA%`},
+			{`100%`, `100%`},
+			{`100%%APPDATA%`, `100%C:\Users\alice\AppData\Roaming`},
+			{`!APPDATA!\App`, `!APPDATA!\App`},
+			{`%BANG%`, `!APPDATA!`},
+			{`$HOME`, `$HOME`},
+		},
+	}
+
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Windows": Windows,
+	}
+
+	for implName, tests := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			lookup := EnvMap(p, env)
+			for _, test := range tests {
+				t.Run(test.s, func(t *testing.T) {
+					if got := ExpandEnv(p, test.s, lookup); got != test.want {
+						t.Errorf("wrong result for %s ExpandEnv(%q)\ngot:  %s\nwant: %s", implName, test.s, got, test.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestExpandEnvDelayed(t *testing.T) {
+	env := map[string]string{
+		"APPDATA": `C:\Users\alice\AppData\Roaming`,
+		"BANG":    "!APPDATA!",
+	}
+	tests := []struct {
+		s, want string
+	}{
+		{`!APPDATA!\App`, `C:\Users\alice\AppData\Roaming\App`},
+		{`!appdata!\App`, `C:\Users\alice\AppData\Roaming\App`},
+		{`%BANG%\App`, `C:\Users\alice\AppData\Roaming\App`},
+		{`!UNSET!\App`, `!UNSET!\App`},
+		{`Hello!`, `Hello!`},
+	}
+
+	lookup := EnvMap(Windows, env)
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			if got := ExpandEnvDelayed(Windows, test.s, lookup); got != test.want {
+				t.Errorf("wrong result for ExpandEnvDelayed(%q)\ngot:  %s\nwant: %s", test.s, got, test.want)
+			}
+		})
+	}
+
+	// For Unix, delayed expansion is the same as normal expansion
+	unixLookup := EnvMap(Unix, map[string]string{"HOME": "/home/alice"})
+	if got, want := ExpandEnvDelayed(Unix, "!HOME!$HOME", unixLookup), "!HOME!/home/alice"; got != want {
+		t.Errorf("wrong result for Unix ExpandEnvDelayed\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestEnvMap(t *testing.T) {
+	env := map[string]string{
+		"Path": "a",
+		"PATH": "b",
+	}
+
+	if got, _ := EnvMap(Windows, env)("PATH"); got != "b" {
+		t.Errorf("Windows lookup didn't prefer exact match; got %q", got)
+	}
+	if _, ok := EnvMap(Unix, env)("path"); ok {
+		t.Errorf("Unix lookup matched case-insensitively")
+	}
+	if got, ok := EnvMap(Windows, map[string]string{"Path": "a"})("PATH"); !ok || got != "a" {
+		t.Errorf("Windows lookup didn't match case-insensitively; got %q, %t", got, ok)
+	}
+
+	// Map iteration order is random, so repeat the lookup to make sure
+	// the same key always wins.
+	for i := 0; i < 20; i++ {
+		if got, _ := EnvMap(Windows, env)("path"); got != "b" {
+			t.Fatalf("Windows lookup with several inexact matches didn't prefer the first key; got %q", got)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ErrNotRelative is the error wrapped by a RelError, for use with errors.Is.
+var ErrNotRelative = errors.New("path cannot be made relative")
+
+// RelError is the type of error returned by Rel when the target path cannot
+// be made relative to the base path, because they have different volume
+// names, because only one of them is rooted, or because the base path
+// begins with more ".." components than the target.
+type RelError struct {
+	Base, Target string
+}
+
+func (e *RelError) Error() string {
+	return "can't make " + e.Target + " relative to " + e.Base
+}
+
+func (e *RelError) Unwrap() error {
+	return ErrNotRelative
+}
+
+var (
+	// ErrNotRemotePath is the error wrapped by a RemotePathError for a
+	// string that does not have a host portion followed by a colon.
+	ErrNotRemotePath = errors.New("not in the host:path form")
+
+	// ErrEmptyHost is the error wrapped by a RemotePathError for a remote
+	// path whose host portion is empty.
+	ErrEmptyHost = errors.New("empty host name")
+
+	// ErrUnclosedHost is the error wrapped by a RemotePathError for a remote
+	// path whose host begins with a bracket, as for an IPv6 address, but is
+	// not followed by "]:".
+	ErrUnclosedHost = errors.New("missing ']:' after bracketed host")
+
+	// ErrRemotePathNotAbs is the error wrapped by a RemotePathError for a
+	// remote path that must be absolute but is not.
+	ErrRemotePathNotAbs = errors.New("path must be absolute")
+
+	// ErrEmptyUser is the error wrapped by a RemotePathError for a remote
+	// path with an "@" but no user name before it.
+	ErrEmptyUser = errors.New("empty user name")
+)
+
+// RemotePathError is the type of error returned by ParseNFSPath and
+// ParseSCPPath for a string that is not a valid remote path. It wraps one of
+// ErrNotRemotePath, ErrEmptyHost, ErrUnclosedHost, ErrRemotePathNotAbs or
+// ErrEmptyUser.
+type RemotePathError struct {
+	// Path is the string that could not be parsed.
+	Path string
+
+	Err error
+}
+
+func (e *RemotePathError) Error() string {
+	return fmt.Sprintf("invalid remote path %q: %s", e.Path, e.Err)
+}
+
+func (e *RemotePathError) Unwrap() error {
+	return e.Err
+}
+
+// URLErrorReason describes why a URL could not be converted into a path, or
+// a path into a URL.
+//
+// Each reason is also an error, wrapped by a URLError, and so a caller can
+// use errors.Is to check for a specific reason, as in
+// errors.Is(err, paths.NonLocalHost). The reasons apply to URLs of any
+// scheme that this package converts, such as file:, smb:, nfs:, sftp: and
+// s3:, and URLError.Scheme gives the scheme involved.
+type URLErrorReason uint8
+
+const (
+	// NonLocalHost means that the URL refers to a host other than the
+	// expected one, such as a host other than the local host in a file:
+	// URL for a path implementation that has no syntax for paths on other
+	// hosts, or a bucket other than the expected one in an s3: URL.
+	NonLocalHost URLErrorReason = iota + 1
+
+	// UserInfo means that the URL has a user portion, which is not allowed
+	// for its scheme.
+	UserInfo
+
+	// BadScheme means that the URL has a scheme other than the one, or
+	// ones, expected by the conversion.
+	BadScheme
+
+	// MissingDrive means that a Windows file: URL for a local path does
+	// not begin with a drive letter.
+	MissingDrive
+
+	// MissingShare means that a URL that refers to a Windows path on
+	// another host, such as a file: or smb: URL, does not include a share
+	// name.
+	MissingShare
+
+	// RelativePath means that the path is not absolute, and so cannot be
+	// represented as a URL, or that a URL does not contain an absolute
+	// path.
+	RelativePath
+
+	// MissingHost means that the URL has no hostname, but the conversion
+	// requires one, or that a path for a URL that requires a hostname has
+	// no host.
+	MissingHost
+
+	// UNCPath means that the path is a Windows UNC path, which has no
+	// representation in URLs of the expected scheme.
+	UNCPath
+)
+
+func (r URLErrorReason) Error() string {
+	return r.message("file")
+}
+
+// message returns the description of the reason for a URL with the given
+// scheme.
+func (r URLErrorReason) message(scheme string) string {
+	switch r {
+	case NonLocalHost:
+		return "only local " + scheme + ": URLs are allowed"
+	case UserInfo:
+		return "user portion not allowed in " + scheme + ": URLs"
+	case BadScheme:
+		return scheme + ": is the only allowed URL scheme"
+	case MissingDrive:
+		return "local " + scheme + ": URLs must begin with a drive letter and then a colon in the path portion"
+	case MissingShare:
+		return scheme + ": URLs with a hostname must include a share name in the path portion"
+	case RelativePath:
+		return scheme + ": URLs can only refer to absolute paths"
+	case MissingHost:
+		return scheme + ": URLs must include a hostname"
+	case UNCPath:
+		return scheme + ": URLs cannot refer to UNC paths"
+	default:
+		return "invalid URL"
+	}
+}
+
+// URLError is the type of error returned by FromURL when a URL cannot be
+// converted into a path, and by the other functions in this package that
+// convert between paths and URLs. It wraps its Reason.
+type URLError struct {
+	// URL is the URL that could not be converted, if any. It is nil for
+	// an error converting a path into a URL, or for a URL that could not
+	// be parsed by net/url.
+	URL    *url.URL
+	Reason URLErrorReason
+
+	// Scheme is the URL scheme that was expected, if it is not "file".
+	Scheme string
+}
+
+func (e *URLError) Error() string {
+	if e.Scheme != "" {
+		return e.Reason.message(e.Scheme)
+	}
+	return e.Reason.Error()
+}
+
+func (e *URLError) Unwrap() error {
+	return e.Reason
+}
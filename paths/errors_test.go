@@ -0,0 +1,97 @@
+package paths
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestRelError(t *testing.T) {
+	tests := []struct {
+		p            P
+		base, target string
+	}{
+		{Unix, "/a", "b"},
+		{Unix, "../a", "b"},
+		{Windows, `C:\a`, `D:\a`},
+		{Windows, `C:\a`, `\a`},
+		{Slash, "a", "/b"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.base+" "+test.target, func(t *testing.T) {
+			_, err := test.p.Rel(test.base, test.target)
+			var relErr *RelError
+			if !errors.As(err, &relErr) {
+				t.Fatalf("wrong error for Rel(%q, %q)\ngot:  %#v\nwant: a RelError", test.base, test.target, err)
+			}
+			if relErr.Base != test.base || relErr.Target != test.target {
+				t.Errorf("wrong paths in RelError\ngot:  %q, %q\nwant: %q, %q", relErr.Base, relErr.Target, test.base, test.target)
+			}
+			if !errors.Is(err, ErrNotRelative) {
+				t.Errorf("error does not wrap ErrNotRelative")
+			}
+			if got, want := err.Error(), "can't make "+test.target+" relative to "+test.base; got != want {
+				t.Errorf("wrong error message\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestURLError(t *testing.T) {
+	tests := []struct {
+		p    P
+		url  string
+		want URLErrorReason
+	}{
+		{Unix, "file://example.com/a", NonLocalHost},
+		{Unix, "file://user@localhost/a", UserInfo},
+		{Unix, "http://localhost/a", BadScheme},
+		{Slash, "file://example.com/a", NonLocalHost},
+		{Windows, "file://user@host/share/a", UserInfo},
+		{Windows, "https://host/share/a", BadScheme},
+		{Windows, "file:///a/b", MissingDrive},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			u, err := url.Parse(test.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = test.p.FromURL(u)
+			var urlErr *URLError
+			if !errors.As(err, &urlErr) {
+				t.Fatalf("wrong error for FromURL(%q)\ngot:  %#v\nwant: a URLError", test.url, err)
+			}
+			if urlErr.Reason != test.want || urlErr.URL != u {
+				t.Errorf("wrong URLError for FromURL(%q)\ngot:  %#v\nwant: reason %d", test.url, urlErr, test.want)
+			}
+			if !errors.Is(err, test.want) {
+				t.Errorf("error does not wrap its reason")
+			}
+			if got, want := err.Error(), test.want.Error(); got != want {
+				t.Errorf("wrong error message\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestURLErrorScheme(t *testing.T) {
+	tests := []struct {
+		err  *URLError
+		want string
+	}{
+		{&URLError{Reason: BadScheme, Scheme: "smb"}, "smb: is the only allowed URL scheme"},
+		{&URLError{Reason: MissingHost, Scheme: "nfs"}, "nfs: URLs must include a hostname"},
+		{&URLError{Reason: UserInfo, Scheme: "s3"}, "user portion not allowed in s3: URLs"},
+		{&URLError{Reason: UNCPath, Scheme: "ssh"}, "ssh: URLs cannot refer to UNC paths"},
+		{&URLError{Reason: BadScheme}, "file: is the only allowed URL scheme"},
+	}
+
+	for _, test := range tests {
+		if got := test.err.Error(); got != test.want {
+			t.Errorf("wrong error message\ngot:  %s\nwant: %s", got, test.want)
+		}
+	}
+}
@@ -79,3 +79,28 @@ func ExampleP_Clean() {
 	// a/c
 	// c:\e
 }
+
+func ExamplePowerShellQuote() {
+	path := `C:\Users\o'brien\logs\[2019]`
+	fmt.Println("Get-Item -LiteralPath " + paths.PowerShellQuote(path))
+	fmt.Println("Get-Item -Path " + paths.PowerShellQuote(paths.PowerShellEscapeWildcards(path)))
+
+	// Output:
+	// Get-Item -LiteralPath 'C:\Users\o''brien\logs\[2019]'
+	// Get-Item -Path 'C:\Users\o''brien\logs\`[2019`]'
+}
+
+func ExampleExpandEnv() {
+	unixEnv := paths.EnvMap(paths.Unix, map[string]string{
+		"HOME": "/home/alice",
+	})
+	windowsEnv := paths.EnvMap(paths.Windows, map[string]string{
+		"ProgramFiles": `C:\Program Files`,
+	})
+	fmt.Println(paths.ExpandEnv(paths.Unix, "${XDG_DATA_HOME:-$HOME/.local/share}/app", unixEnv))
+	fmt.Println(paths.ExpandEnv(paths.Windows, `%PROGRAMFILES%\App`, windowsEnv))
+
+	// Output:
+	// /home/alice/.local/share/app
+	// C:\Program Files\App
+}
@@ -0,0 +1,220 @@
+package paths
+
+import (
+	"net/url"
+	"strings"
+)
+
+// FileURI returns the RFC 8089 file: URI for the given absolute path, using
+// the syntax of the given path implementation.
+//
+// Unlike ToURL, which relies on the escaping rules of net/url, FileURI
+// encodes each byte of the path deliberately: bytes that are not allowed
+// in a URI path segment, including any bytes of the path that are not valid
+// UTF-8, are percent-encoded, and so FileURI can represent any Unix path.
+// Windows paths are expected to be in UTF-8, or in WTF-8 for a name
+// containing unpaired UTF-16 surrogates, and are encoded byte-for-byte.
+//
+// A Unix path produces a URI like "file:///home/alice/a%20b.txt". A Windows
+// path with a drive letter produces a URI like "file:///C:/Users/a%20b.txt",
+// and a UNC path produces a URI with the server as its authority, like
+// "file://server/share/a.txt". Windows paths with the `\\?\` prefix are
+// treated as the equivalent path without the prefix.
+//
+// Returns a *URLError with reason RelativePath if the path is not absolute,
+// or if it is a Windows path without a drive letter or UNC share.
+func FileURI(p P, path string) (string, error) {
+	im := syntaxOf(p)
+	if im != windowsImpl {
+		if !p.IsAbs(path) {
+			return "", &URLError{Reason: RelativePath}
+		}
+		return "file://" + escapeFileURI(p.Clean(path)), nil
+	}
+
+	// Windows considers reserved device names to be absolute, but they have
+	// no file: URI, while a UNC share without a trailing separator is
+	// not considered to be absolute but does have one.
+	path = p.Clean(windowsTrimLongPrefix(path))
+	vol := p.VolumeName(path)
+	if vol == "" || (len(vol) == 2 && !p.IsAbs(path)) {
+		return "", &URLError{Reason: RelativePath}
+	}
+	if len(vol) == 2 {
+		return "file:///" + escapeFileURI(im.toSlash(path)), nil
+	}
+	// A UNC path's server becomes the authority, and its share becomes
+	// the first segment of the URI path.
+	server, rest, _ := im.windowsUNCHost(path)
+	return "file://" + escapeFileURI(server) + escapeFileURI(rest), nil
+}
+
+// ParseFileURI returns the path represented by the given RFC 8089 file: URI,
+// using the syntax of the given path implementation. It accepts any URI
+// produced by FileURI, along with the other forms described in RFC 8089
+// and its appendices.
+//
+// For Windows, a local path may be written as "file:///C:/a", "file:/C:/a"
+// or "file:C:/a", with either a colon or a vertical bar after the drive
+// letter. A UNC path may be written either with the server as the authority,
+// as in "file://server/share/a", or with an empty authority, as in
+// "file:////server/share/a". Unix has no syntax for paths on another host,
+// and so accepts only local URIs like "file:///a", "file:/a" or
+// "file://localhost/a".
+//
+// Percent-encoded bytes are decoded without regard to whether they form
+// valid UTF-8. A percent sign that does not begin a valid percent-encoding
+// is taken literally. Any query or fragment is ignored.
+//
+// Returns a *URLError describing why the URI could not be converted if it
+// is not a file: URI, if it has a user portion, if it refers to another host
+// when that is not supported, or if it does not contain an absolute path.
+func ParseFileURI(p P, uri string) (string, error) {
+	im := syntaxOf(p)
+	fail := func(reason URLErrorReason) (string, error) {
+		u, _ := url.Parse(uri)
+		return "", &URLError{URL: u, Reason: reason}
+	}
+
+	scheme, rest, ok := strings.Cut(uri, ":")
+	if !ok || !strings.EqualFold(scheme, "file") {
+		return fail(BadScheme)
+	}
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+
+	var host string
+	if strings.HasPrefix(rest, "//") {
+		host, rest = cutAuthority(rest[2:])
+		if strings.Contains(host, "@") {
+			return fail(UserInfo)
+		}
+		if strings.EqualFold(host, "localhost") {
+			host = ""
+		}
+		if host == "" && strings.HasPrefix(rest, "//") {
+			// The UNC variant with the server at the start of the path
+			host, rest = cutAuthority(rest[2:])
+			if host == "" {
+				return fail(MissingShare)
+			}
+		}
+		host = unescapeFileURI(host)
+	}
+	path := unescapeFileURI(rest)
+
+	if host != "" {
+		if im != windowsImpl {
+			return fail(NonLocalHost)
+		}
+		path = p.Clean(`\\` + host + im.fromSlash(path))
+		if !im.isUNC(path) {
+			return fail(MissingShare)
+		}
+		return path, nil
+	}
+
+	if im != windowsImpl {
+		if !strings.HasPrefix(path, "/") {
+			return fail(RelativePath)
+		}
+		return p.Clean(path), nil
+	}
+	if len(path) >= 3 && path[0] == '/' && isWindowsDrive(path[1:]) {
+		path = path[1:]
+	}
+	if !isWindowsDrive(path) {
+		return fail(MissingDrive)
+	}
+	return p.Clean(path[:1] + `:\` + path[2:]), nil
+}
+
+// isWindowsDrive returns true if the given slash-separated path begins with
+// a drive letter followed by either a colon or a vertical bar, and then
+// either a separator or nothing at all.
+func isWindowsDrive(path string) bool {
+	if len(path) < 2 || (path[1] != ':' && path[1] != '|') {
+		return false
+	}
+	if c := path[0]; !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+		return false
+	}
+	return len(path) == 2 || path[2] == '/' || path[2] == '\\'
+}
+
+// windowsTrimLongPrefix removes the `\\?\` prefix that disables path
+// normalization, returning the equivalent drive-letter or UNC path.
+func windowsTrimLongPrefix(path string) string {
+	if len(path) < 4 || !isSlash(path[0]) || !isSlash(path[1]) || path[2] != '?' || !isSlash(path[3]) {
+		return path
+	}
+	rest := path[4:]
+	if len(rest) >= 4 && strings.EqualFold(rest[:3], "UNC") && isSlash(rest[3]) {
+		return `\\` + rest[4:]
+	}
+	return rest
+}
+
+// cutAuthority splits the remainder of a URI after the "//" that introduces
+// its authority into the authority and the path that follows it.
+func cutAuthority(s string) (authority, path string) {
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		return s[:i], s[i:]
+	}
+	return s, ""
+}
+
+// escapeFileURI percent-encodes every byte of the given string that isn't
+// allowed unescaped in the path of a URI, other than slashes.
+func escapeFileURI(s string) string {
+	const hex = "0123456789ABCDEF"
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+			buf.WriteByte(c)
+		case strings.IndexByte("-._~!$&'()*+,;=:@/", c) >= 0:
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(hex[c>>4])
+			buf.WriteByte(hex[c&0xf])
+		}
+	}
+	return buf.String()
+}
+
+// unescapeFileURI decodes the percent-encoded bytes in the given string,
+// leaving any percent sign that doesn't begin a valid encoding unchanged.
+func unescapeFileURI(s string) string {
+	if strings.IndexByte(s, '%') < 0 {
+		return s
+	}
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			buf.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+			continue
+		}
+		buf.WriteByte(s[i])
+	}
+	return buf.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
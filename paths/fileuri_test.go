@@ -0,0 +1,171 @@
+package paths
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFileURI(t *testing.T) {
+	type Test struct {
+		path, want string
+	}
+
+	implTests := map[string][]Test{
+		"Unix": {
+			{"/", "file:///"},
+			{"/home/alice/a.txt", "file:///home/alice/a.txt"},
+			{"/home/alice/a b.txt", "file:///home/alice/a%20b.txt"},
+			{"/100%/a#b?c", "file:///100%25/a%23b%3Fc"},
+			{"/caf\u00e9", "file:///caf%C3%A9"},
+			{"/bad\xff\xfe", "file:///bad%FF%FE"},
+			{"/a:b@c/x+y=z;(1)", "file:///a:b@c/x+y=z;(1)"},
+			{`/a\b`, "file:///a%5Cb"},
+			{"//a//b/../c/", "file:///a/c"},
+			{"a/b", "err"},
+		},
+		"Windows": {
+			{`C:\`, "file:///C:/"},
+			{`C:\Users\alice\a b.txt`, "file:///C:/Users/alice/a%20b.txt"},
+			{`c:/Program Files (x86)/100%`, "file:///c:/Program%20Files%20(x86)/100%25"},
+			{"C:\\caf\u00e9\\\xed\xa0\x80", "file:///C:/caf%C3%A9/%ED%A0%80"},
+			{`\\server\share`, "file://server/share"},
+			{`\\server\share\dir\a#b.txt`, "file://server/share/dir/a%23b.txt"},
+			{`\\?\C:\very\long`, "file:///C:/very/long"},
+			{`\\?\UNC\server\share\x`, "file://server/share/x"},
+			{`C:a`, "err"},
+			{`\a`, "err"},
+			{`a\b`, "err"},
+			{`CON`, "err"},
+			{`\\.\pipe\x`, "err"},
+		},
+	}
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Windows": Windows,
+	}
+
+	for implName, tests := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			for _, test := range tests {
+				t.Run(test.path, func(t *testing.T) {
+					got, err := FileURI(p, test.path)
+					if test.want == "err" {
+						if !errors.Is(err, RelativePath) {
+							t.Errorf("wrong result for FileURI(%q)\ngot:  %s, %v\nwant: RelativePath error", test.path, got, err)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error for FileURI(%q): %s", test.path, err)
+					}
+					if got != test.want {
+						t.Errorf("wrong result for FileURI(%q)\ngot:  %s\nwant: %s", test.path, got, test.want)
+					}
+
+					// The result must round-trip back to the same path,
+					// without any long path prefix.
+					want := p.Clean(windowsTrimLongPrefix(test.path))
+					if p == Unix {
+						want = p.Clean(test.path)
+					}
+					back, err := ParseFileURI(p, got)
+					if err != nil {
+						t.Fatalf("unexpected error for ParseFileURI(%q): %s", got, err)
+					}
+					if back != want {
+						t.Errorf("wrong result for ParseFileURI(%q)\ngot:  %q\nwant: %q", got, back, want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestParseFileURI(t *testing.T) {
+	type Test struct {
+		uri, want string
+	}
+
+	// A want value that is the name of a URLErrorReason means that the
+	// result must be an error with that reason.
+	reasons := map[string]URLErrorReason{
+		"NonLocalHost": NonLocalHost,
+		"UserInfo":     UserInfo,
+		"BadScheme":    BadScheme,
+		"MissingDrive": MissingDrive,
+		"MissingShare": MissingShare,
+		"RelativePath": RelativePath,
+	}
+
+	implTests := map[string][]Test{
+		"Unix": {
+			{"file:///a/b", "/a/b"},
+			{"file:/a/b", "/a/b"},
+			{"FILE:///a/b", "/a/b"},
+			{"file://localhost/a/b", "/a/b"},
+			{"file://LocalHost/a/b", "/a/b"},
+			{"file:///a%20b/c%2", "/a b/c%2"},
+			{"file:///a%zzb", "/a%zzb"},
+			{"file:///a/b?query#fragment", "/a/b"},
+			{"file:///bad%ff", "/bad\xff"},
+			{"file:///a/../b/", "/b"},
+			{"file://server/share/x", "NonLocalHost"},
+			{"file:////server/share/x", "NonLocalHost"},
+			{"file://user@localhost/a", "UserInfo"},
+			{"http:///a", "BadScheme"},
+			{"/a/b", "BadScheme"},
+			{"file:a/b", "RelativePath"},
+			{"file:", "RelativePath"},
+		},
+		"Windows": {
+			{"file:///C:/a/b", `C:\a\b`},
+			{"file:/C:/a/b", `C:\a\b`},
+			{"file:C:/a/b", `C:\a\b`},
+			{"file:///c|/a/b", `c:\a\b`},
+			{"file://localhost/C:/a", `C:\a`},
+			{"file:///C:", `C:\`},
+			{"file:///C:/a%20b%5Cc", `C:\a b\c`},
+			{"file:///C%3A/a", `C:\a`},
+			{"file://server/share/a/b", `\\server\share\a\b`},
+			{"file:////server/share/a/b", `\\server\share\a\b`},
+			{"file://server/share", `\\server\share`},
+			{"file://server", "MissingShare"},
+			{"file://server/", "MissingShare"},
+			{"file://///x", "MissingShare"},
+			{"file://user@server/share", "UserInfo"},
+			{"file:///a/b", "MissingDrive"},
+			{"file:///CD:/a", "MissingDrive"},
+			{"file:a/b", "MissingDrive"},
+			{"smb://server/share", "BadScheme"},
+		},
+	}
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Windows": Windows,
+	}
+
+	for implName, tests := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			for _, test := range tests {
+				t.Run(test.uri, func(t *testing.T) {
+					got, err := ParseFileURI(p, test.uri)
+					if reason, ok := reasons[test.want]; ok {
+						var urlErr *URLError
+						if !errors.As(err, &urlErr) || urlErr.Reason != reason {
+							t.Errorf("wrong result for ParseFileURI(%q)\ngot:  %q, %v\nwant: %s error", test.uri, got, err, test.want)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error for ParseFileURI(%q): %s", test.uri, err)
+					}
+					if got != test.want {
+						t.Errorf("wrong result for ParseFileURI(%q)\ngot:  %q\nwant: %q", test.uri, got, test.want)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package paths
+
+import (
+	"fmt"
+	"io/fs"
+	slashpath "path"
+	"strings"
+)
+
+// fsName returns the io/fs name that corresponds to the given native path,
+// for a filesystem whose root directory "." corresponds to the native path
+// root. The path and the root must either both be absolute or both be
+// relative.
+//
+// Returns an error if the path is not within the root.
+func fsName(p P, root, path string) (string, error) {
+	rest, ok := trimComponents(p, path, root)
+	if !ok {
+		return "", fmt.Errorf("path %s is not within %s", path, root)
+	}
+	for _, elem := range rest {
+		if elem == ".." {
+			return "", fmt.Errorf("path %s is not within %s", path, root)
+		}
+	}
+	if len(rest) == 0 {
+		return ".", nil
+	}
+	name := strings.Join(rest, "/")
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("path %s cannot be represented as an fs.FS name", path)
+	}
+	return name, nil
+}
+
+// resolveFold returns the name of the file in the given filesystem whose name
+// matches the given name when each element is compared case-insensitively,
+// as Windows does. An element that matches exactly is preferred over one
+// that matches only case-insensitively.
+//
+// Returns an error wrapping fs.ErrNotExist if there is no matching file.
+func resolveFold(fsys fs.FS, name string) (string, error) {
+	if _, err := fs.Stat(fsys, name); err == nil {
+		return name, nil
+	}
+	if name == "." {
+		return "", &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+
+	dir := "."
+	for _, elem := range strings.Split(name, "/") {
+		next := slashpath.Join(dir, elem)
+		if _, err := fs.Stat(fsys, next); err == nil {
+			dir = next
+			continue
+		}
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			return "", &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+		}
+		found := false
+		for _, entry := range entries {
+			if strings.EqualFold(entry.Name(), elem) {
+				dir = slashpath.Join(dir, entry.Name())
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	return dir, nil
+}
@@ -0,0 +1,184 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WindowsFullPath returns the full path that the Windows function
+// GetFullPathNameW would return for the given path, given the working
+// directory cwd and the per-drive working directories in drives, which are
+// interpreted as for WindowsAbs.
+//
+// This is a more faithful emulation of Windows path normalization than
+// the combination of WindowsAbs and Windows.Clean, and so is suitable for
+// predicting which file a Windows system will actually open for a given
+// path. In particular, it differs from Clean in the following ways:
+//
+//   - Trailing periods and spaces are removed from the final component,
+//     and a single trailing period is removed from the other components.
+//   - A trailing separator is retained.
+//   - The device namespace prefixes \\.\ and \\?\ are treated as the root
+//     of the path, and so a ".." component can remove the device name that
+//     follows them.
+//   - A final component that names a legacy DOS device, such as "CON",
+//     "com1.txt" or "LPT1 .log", produces a device path like \\.\COM1,
+//     except in UNC and device paths. This follows the behavior of Windows
+//     versions prior to Windows 11.
+//
+// Returns an error if the path is empty or if the working directory is
+// not absolute.
+func WindowsFullPath(cwd string, drives map[string]string, path string) (string, error) {
+	if path == "" {
+		return "", errors.New("path is empty")
+	}
+	if !Windows.IsAbs(cwd) {
+		return "", fmt.Errorf("working directory %s is not absolute", cwd)
+	}
+	path = strings.Replace(path, "/", `\`, -1)
+
+	if !isWindowsDevicePath(path) && !strings.HasPrefix(path, `\\`) {
+		if name := windowsLegacyDeviceName(path); name != "" {
+			return `\\.\` + name, nil
+		}
+	}
+
+	raw := path
+	vol := Windows.VolumeName(path)
+	switch {
+	case isWindowsDevicePath(path) || strings.HasPrefix(path, `\\`):
+		// Already absolute
+	case len(vol) == 2 && len(path) > 2 && path[2] == '\\':
+		// Already absolute
+	case len(vol) == 2:
+		// Relative to the working directory of a particular drive
+		dir, err := WindowsAbs(cwd, drives, vol)
+		if err != nil {
+			return "", err
+		}
+		raw = dir
+		if len(path) > 2 {
+			raw += `\` + path[2:]
+		}
+	case path[0] == '\\':
+		// Relative to the root of the current volume
+		raw = Windows.VolumeName(cwd) + path
+	default:
+		raw = cwd + `\` + path
+	}
+	return windowsNormalizeFull(strings.Replace(raw, "/", `\`, -1)), nil
+}
+
+// windowsNormalizeFull normalizes an absolute Windows path in the way that
+// GetFullPathNameW does, once any relative path has been resolved against
+// a working directory.
+func windowsNormalizeFull(path string) string {
+	var root, rest string
+	switch {
+	case isWindowsDevicePath(path):
+		if len(path) < 4 {
+			path += `\`
+		}
+		root, rest = path[:4], path[4:]
+	case strings.HasPrefix(path, `\\`):
+		// UNC path. The root is the server name and the share name, if
+		// present, whether or not they are followed by more components.
+		n := 2
+		for elems := 0; n < len(path) && elems < 2; elems++ {
+			for n < len(path) && path[n] == '\\' && elems > 0 {
+				n++
+			}
+			for n < len(path) && path[n] != '\\' {
+				n++
+			}
+		}
+		root, rest = path[:n], path[n:]
+	default:
+		root, rest = path[:2], path[2:]
+	}
+
+	segs := strings.Split(rest, `\`)
+	trailingSep := strings.HasSuffix(rest, `\`)
+	var out []string
+	for i, seg := range segs {
+		final := i == len(segs)-1
+		switch {
+		case seg == "" || seg == ".":
+			continue
+		case seg == "..":
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+			continue
+		case final:
+			seg = strings.TrimRight(seg, ". ")
+			if seg == "" {
+				continue
+			}
+		case strings.HasSuffix(seg, ".") && !strings.HasSuffix(seg, ".."):
+			seg = seg[:len(seg)-1]
+		}
+		out = append(out, seg)
+	}
+
+	var buf strings.Builder
+	buf.WriteString(root)
+	if len(out) == 0 {
+		if rest != "" && !strings.HasSuffix(root, `\`) {
+			buf.WriteByte('\\')
+		}
+		return buf.String()
+	}
+	for i, seg := range out {
+		if i > 0 || !strings.HasSuffix(root, `\`) {
+			buf.WriteByte('\\')
+		}
+		buf.WriteString(seg)
+	}
+	if trailingSep {
+		buf.WriteByte('\\')
+	}
+	return buf.String()
+}
+
+// isWindowsDevicePath returns true if the given path, which must use only
+// backslashes as separators, begins with one of the device namespace
+// prefixes \\.\ or \\?\.
+func isWindowsDevicePath(path string) bool {
+	if len(path) < 3 || path[0] != '\\' || path[1] != '\\' || (path[2] != '.' && path[2] != '?') {
+		return false
+	}
+	return len(path) == 3 || path[3] == '\\'
+}
+
+// windowsLegacyDeviceName returns the name of the legacy DOS device that the
+// final component of the given path refers to, or an empty string if it
+// does not refer to a device.
+//
+// The device name is recognized regardless of any extension, trailing
+// spaces, or trailing colon, so "con.txt", "CON .log" and "con:" all refer
+// to the console device CON.
+func windowsLegacyDeviceName(path string) string {
+	name := path
+	if i := strings.LastIndexAny(name, `\:`); i >= 0 {
+		if name[i] == ':' && i == len(name)-1 {
+			// A trailing colon is allowed after a device name, but
+			// we need to be careful not to treat "C:" as a device name.
+			name = name[:i]
+			if j := strings.LastIndexAny(name, `\:`); j >= 0 {
+				name = name[j+1:]
+			}
+		} else {
+			name = name[i+1:]
+		}
+	}
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimRight(name, " ")
+	if !isWindowsReservedName(name) {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,136 @@
+package paths
+
+import (
+	"testing"
+)
+
+// windowsFullPathCWD and windowsFullPathDrives are the working directories
+// for the cases in windowsFullPathTests.
+const windowsFullPathCWD = `C:\Users\Alice`
+
+var windowsFullPathDrives = map[string]string{
+	"D:": `D:\work`,
+}
+
+// windowsFullPathTests is the corpus of cases for WindowsFullPath.
+//
+// The expected results follow the rules documented for GetFullPathNameW in
+// the "File path formats on Windows systems" article of the .NET
+// documentation, and have not yet been confirmed by a recorded run on
+// Windows. When the tests run on Windows, TestWindowsFullPathNative in
+// fullpath_windows_test.go checks every expected result against the real
+// GetFullPathNameW, from a working directory that stands in for the one
+// above. A case that fails there should be corrected to the observed
+// result, noting the Windows build it was observed on.
+var windowsFullPathTests = []struct {
+	path, want string
+}{
+	// Absolute paths
+	{`C:\a\b`, `C:\a\b`},
+	{`c:/a/b`, `c:\a\b`},
+	{`C:\a\\b\\\c`, `C:\a\b\c`},
+	{`C:\a\.\b\..\c`, `C:\a\c`},
+	{`C:\..\..\a`, `C:\a`},
+	{`C:\`, `C:\`},
+	{`C:\a\..`, `C:\`},
+
+	// Trailing separators are preserved
+	{`C:\a\b\`, `C:\a\b\`},
+	{`C:\a\b\\`, `C:\a\b\`},
+	{`C:\a\b\.\`, `C:\a\b\`},
+
+	// Trailing periods and spaces
+	{`C:\a\b.`, `C:\a\b`},
+	{`C:\a\b...`, `C:\a\b`},
+	{`C:\a\b. . `, `C:\a\b`},
+	{`C:\a\b .txt `, `C:\a\b .txt`},
+	{`C:\a\b. \`, `C:\a\b. \`},
+	{`C:\a.\b`, `C:\a\b`},
+	{`C:\a..\b`, `C:\a..\b`},
+	{`C:\a\...\b`, `C:\a\...\b`},
+	{`C:\a\...`, `C:\a`},
+
+	// Relative paths
+	{`foo`, `C:\Users\Alice\foo`},
+	{`.\foo\`, `C:\Users\Alice\foo\`},
+	{`..\Bob`, `C:\Users\Bob`},
+	{`..\..\..\..`, `C:\`},
+	{`.`, `C:\Users\Alice`},
+	{`\Windows`, `C:\Windows`},
+	{`/Windows/`, `C:\Windows\`},
+	{`C:`, `C:\Users\Alice`},
+	{`C:foo`, `C:\Users\Alice\foo`},
+	{`D:foo`, `D:\work\foo`},
+	{`d:..\foo`, `D:\foo`},
+	{`E:foo`, `E:\foo`},
+	{`E:`, `E:\`},
+
+	// UNC paths
+	{`\\server\share\a\..\b`, `\\server\share\b`},
+	{`\\server\share\..\..\b`, `\\server\share\b`},
+	{`//server/share/x`, `\\server\share\x`},
+	{`\\server\share`, `\\server\share`},
+	{`\\server\share\`, `\\server\share\`},
+	{`\\server\share\a.`, `\\server\share\a`},
+	{`\\server\share\CON`, `\\server\share\CON`},
+	{`\\server`, `\\server`},
+
+	// Device paths
+	{`\\.`, `\\.\`},
+	{`\\?\`, `\\?\`},
+	{`\\.\COM1`, `\\.\COM1`},
+	{`\\.\C:\a\..\b`, `\\.\C:\b`},
+	{`\\?\C:\a\..\b`, `\\?\C:\b`},
+	{`\\?\C:\..\x`, `\\?\x`},
+	{`//?/C:/a`, `\\?\C:\a`},
+	{`\\.\pipe\name`, `\\.\pipe\name`},
+
+	// Legacy device names
+	{`CON`, `\\.\CON`},
+	{`con.txt`, `\\.\con`},
+	{`C:\dir\COM1.txt`, `\\.\COM1`},
+	{`LPT1 .log`, `\\.\LPT1`},
+	{`NUL:`, `\\.\NUL`},
+	{`COM1:`, `\\.\COM1`},
+	{`C:nul`, `\\.\nul`},
+	{`CONSOLE`, `C:\Users\Alice\CONSOLE`},
+	{`COM10`, `C:\Users\Alice\COM10`},
+	{`CON\foo`, `C:\Users\Alice\CON\foo`},
+	{`xCON.txt`, `C:\Users\Alice\xCON.txt`},
+}
+
+func TestWindowsFullPath(t *testing.T) {
+	const cwd = windowsFullPathCWD
+	drives := windowsFullPathDrives
+
+	for _, test := range windowsFullPathTests {
+		t.Run(test.path, func(t *testing.T) {
+			got, err := WindowsFullPath(cwd, drives, test.path)
+			if err != nil {
+				t.Fatalf("unexpected error for WindowsFullPath(%q): %s", test.path, err)
+			}
+			if got != test.want {
+				t.Errorf("wrong result for WindowsFullPath(%q)\ngot:  %s\nwant: %s", test.path, got, test.want)
+			}
+		})
+	}
+
+	t.Run("UNC working directory", func(t *testing.T) {
+		got, err := WindowsFullPath(`\\server\share\home`, nil, `\other\.\file.`)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if want := `\\server\share\other\file`; got != want {
+			t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := WindowsFullPath(cwd, drives, ""); err == nil {
+			t.Errorf("no error for empty path")
+		}
+		if _, err := WindowsFullPath(`Users\Alice`, drives, "foo"); err == nil {
+			t.Errorf("no error for relative working directory")
+		}
+	})
+}
@@ -0,0 +1,132 @@
+//go:build windows
+
+package paths
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// TestWindowsFullPathNative checks every case in windowsFullPathTests
+// against the real GetFullPathNameW.
+//
+// The working directory windowsFullPathCWD need not exist, and so the test
+// instead works in a new directory with the same name at the same depth on
+// the same drive. It expects the corpus results with the parent of
+// windowsFullPathCWD replaced by the parent of the new directory.
+func TestWindowsFullPathNative(t *testing.T) {
+	drive := Windows.VolumeName(windowsFullPathCWD)
+	if vol := Windows.VolumeName(t.TempDir()); !strings.EqualFold(vol, drive) {
+		t.Skipf("temporary directory is on %s rather than %s", vol, drive)
+	}
+	parent, err := os.MkdirTemp(drive+`\`, "go-paths-")
+	if err != nil {
+		t.Skipf("can't create a directory at the root of %s: %s", drive, err)
+	}
+	defer os.RemoveAll(parent)
+	cwd := Windows.Join(parent, Windows.Base(windowsFullPathCWD))
+	if err := os.Mkdir(cwd, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	oldwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(cwd); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(oldwd)
+	for drive, dir := range windowsFullPathDrives {
+		setDriveWorkingDirectory(t, drive, dir)
+	}
+
+	// Windows 11 no longer maps names like "con.txt" to devices, and so
+	// the legacy device cases only apply to earlier versions.
+	legacyDevices := getFullPathName(t, "con.txt") == `\\.\con`
+	if !legacyDevices {
+		t.Logf("this version of Windows does not map legacy device names with extensions to devices; skipping those cases")
+	}
+
+	corpusParent := Windows.Dir(windowsFullPathCWD)
+	for _, test := range windowsFullPathTests {
+		t.Run(test.path, func(t *testing.T) {
+			if !legacyDevices && windowsLegacyDeviceName(strings.ReplaceAll(test.path, "/", `\`)) != "" {
+				t.Skip("legacy device name")
+			}
+			want := test.want
+			if rest, ok := trimFoldPrefix(want, corpusParent); ok && (rest == "" || rest[0] == '\\') {
+				want = parent + rest
+			}
+
+			native := getFullPathName(t, test.path)
+			if native != want {
+				t.Errorf("wrong result from GetFullPathNameW(%q)\ngot:  %s\nwant: %s (%s in the corpus)", test.path, native, want, test.want)
+			}
+			got, err := WindowsFullPath(cwd, windowsFullPathDrives, test.path)
+			if err != nil {
+				t.Fatalf("unexpected error for WindowsFullPath(%q): %s", test.path, err)
+			}
+			if got != native {
+				t.Errorf("wrong result for WindowsFullPath(%q)\ngot:  %s\nwant: %s (from GetFullPathNameW)", test.path, got, native)
+			}
+		})
+	}
+}
+
+// trimFoldPrefix removes the given prefix from s, ignoring case.
+func trimFoldPrefix(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+// getFullPathName calls GetFullPathNameW for the given path.
+func getFullPathName(t *testing.T, path string) string {
+	t.Helper()
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]uint16, 256)
+	for {
+		n, err := syscall.GetFullPathName(p, uint32(len(buf)), &buf[0], nil)
+		if err != nil {
+			t.Fatalf("GetFullPathNameW(%q) failed: %s", path, err)
+		}
+		if n <= uint32(len(buf)) {
+			return syscall.UTF16ToString(buf[:n])
+		}
+		buf = make([]uint16, n)
+	}
+}
+
+// setDriveWorkingDirectory sets the hidden environment variable, like "=D:",
+// that holds the working directory of a drive other than the current one,
+// restoring it when the test ends.
+func setDriveWorkingDirectory(t *testing.T, drive, dir string) {
+	t.Helper()
+	name, err := syscall.UTF16PtrFromString("=" + strings.ToUpper(drive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := syscall.UTF16PtrFromString(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]uint16, syscall.MAX_PATH)
+	n, _ := syscall.GetEnvironmentVariable(name, &buf[0], uint32(len(buf)))
+	if err := syscall.SetEnvironmentVariable(name, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if n == 0 {
+			syscall.SetEnvironmentVariable(name, nil)
+		} else {
+			syscall.SetEnvironmentVariable(name, &buf[0])
+		}
+	})
+}
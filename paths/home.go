@@ -0,0 +1,78 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// HomeLookup is the type of a function that returns the home directory of
+// the user with the given name on some target system, along with a flag that
+// is true only if the home directory is known. An empty name refers to the
+// current user.
+//
+// The home directories of users on a remote system are not available through
+// package os, so callers of ExpandHome and ContractHome must provide their
+// own lookup function.
+type HomeLookup func(user string) (dir string, ok bool)
+
+// ExpandHome replaces a leading tilde prefix in the given path with the
+// home directory it refers to, as a Unix shell would.
+//
+// A path beginning with "~" followed by a separator or the end of the path
+// refers to the home directory of the current user, while "~name" refers to
+// the home directory of the user with the given name. The result is the
+// home directory joined with the remainder of the path, as with Join.
+// A path that does not begin with a tilde is returned unchanged.
+//
+// The same syntax is accepted for Windows paths, where either slash or
+// backslash may terminate the tilde prefix.
+//
+// Returns an error if the lookup function does not know the requested
+// home directory.
+func ExpandHome(p P, path string, lookup HomeLookup) (string, error) {
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+	im := syntaxOf(p)
+	i := 1
+	for i < len(path) && !im.isPathSeparator(path[i]) {
+		i++
+	}
+	user, rest := path[1:i], path[i:]
+	dir, ok := lookup(user)
+	if !ok {
+		if user == "" {
+			return "", errors.New("home directory for current user is not known")
+		}
+		return "", fmt.Errorf("home directory for user %q is not known", user)
+	}
+	return p.Join(dir, rest), nil
+}
+
+// ContractHome is the inverse of ExpandHome, replacing the current user's
+// home directory at the start of the given path with a tilde, for more
+// compact display.
+//
+// The home directory is compared with the leading components of the path
+// using the case rules of the given implementation, so for Windows
+// "C:\Users\Alice" matches "c:\users\alice\proj" to produce "~\proj".
+// The remainder of the path is cleaned, but retains its original spelling.
+//
+// If the path is not within the current user's home directory, or the
+// current user's home directory isn't known, the path is returned unchanged.
+func ContractHome(p P, path string, lookup HomeLookup) string {
+	dir, ok := lookup("")
+	if !ok || !p.IsAbs(dir) {
+		return path
+	}
+	rest, ok := trimComponents(p, path, dir)
+	if !ok {
+		return path
+	}
+	if len(rest) == 0 {
+		return "~"
+	}
+	sep := string(syntaxOf(p).separator())
+	return "~" + sep + strings.Join(rest, sep)
+}
@@ -0,0 +1,142 @@
+package paths
+
+import (
+	"testing"
+)
+
+func TestExpandHome(t *testing.T) {
+	type Test struct {
+		path, want string
+	}
+
+	homes := map[string]map[string]string{
+		"Unix": {
+			"":      "/home/alice",
+			"alice": "/home/alice",
+			"bob":   "/srv/bob/",
+		},
+		"Windows": {
+			"":      `C:\Users\Alice`,
+			"alice": `C:\Users\Alice`,
+			"bob":   `\\fileserver\homes\bob`,
+		},
+	}
+
+	implTests := map[string][]Test{
+		"Unix": {
+			{"~", "/home/alice"},
+			{"~/", "/home/alice"},
+			{"~/proj", "/home/alice/proj"},
+			{"~/proj/../x", "/home/alice/x"},
+			{"~alice/proj", "/home/alice/proj"},
+			{"~bob", "/srv/bob"},
+			{"~bob/.ssh/config", "/srv/bob/.ssh/config"},
+			{"/etc/hosts", "/etc/hosts"},
+			{"foo/~", "foo/~"},
+			{"", ""},
+			{"~carol/proj", "err"},
+		},
+		"Windows": {
+			{"~", `C:\Users\Alice`},
+			{`~\proj`, `C:\Users\Alice\proj`},
+			{`~/proj`, `C:\Users\Alice\proj`},
+			{`~bob\docs`, `\\fileserver\homes\bob\docs`},
+			{`C:\Windows`, `C:\Windows`},
+			{`~carol`, "err"},
+		},
+	}
+
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Windows": Windows,
+	}
+
+	for implName, tests := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			lookup := func(user string) (string, bool) {
+				dir, ok := homes[implName][user]
+				return dir, ok
+			}
+			for _, test := range tests {
+				t.Run(test.path, func(t *testing.T) {
+					got, err := ExpandHome(p, test.path, lookup)
+					if test.want == "err" {
+						if err == nil {
+							t.Errorf("wrong result for %s ExpandHome(%q)\ngot:  %s\nwant: an error", implName, test.path, got)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error for %s ExpandHome(%q): %s", implName, test.path, err)
+					}
+					if got != test.want {
+						t.Errorf("wrong result for %s ExpandHome(%q)\ngot:  %s\nwant: %s", implName, test.path, got, test.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestContractHome(t *testing.T) {
+	type Test struct {
+		path, want string
+	}
+
+	homes := map[string]string{
+		"Unix":    "/home/alice/",
+		"Windows": `C:\Users\Alice`,
+	}
+
+	implTests := map[string][]Test{
+		"Unix": {
+			{"/home/alice", "~"},
+			{"/home/alice/", "~"},
+			{"/home/alice/proj", "~/proj"},
+			{"/home/alice//proj/./src", "~/proj/src"},
+			{"/home/alicex/proj", "/home/alicex/proj"},
+			{"/home/Alice/proj", "/home/Alice/proj"},
+			{"/home", "/home"},
+			{"home/alice/proj", "home/alice/proj"},
+		},
+		"Windows": {
+			{`C:\Users\Alice`, `~`},
+			{`C:\Users\Alice\proj`, `~\proj`},
+			{`c:\users\alice\Proj`, `~\Proj`},
+			{`c:/users/alice/proj/src`, `~\proj\src`},
+			{`D:\Users\Alice\proj`, `D:\Users\Alice\proj`},
+			{`C:\Users\AliceX`, `C:\Users\AliceX`},
+			{`\Users\Alice\proj`, `\Users\Alice\proj`},
+		},
+	}
+
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Windows": Windows,
+	}
+
+	for implName, tests := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			lookup := func(user string) (string, bool) {
+				if user != "" {
+					return "", false
+				}
+				return homes[implName], true
+			}
+			for _, test := range tests {
+				t.Run(test.path, func(t *testing.T) {
+					if got := ContractHome(p, test.path, lookup); got != test.want {
+						t.Errorf("wrong result for %s ContractHome(%q)\ngot:  %s\nwant: %s", implName, test.path, got, test.want)
+					}
+				})
+			}
+		})
+	}
+
+	noHome := func(user string) (string, bool) { return "", false }
+	if got := ContractHome(Unix, "/home/alice", noHome); got != "/home/alice" {
+		t.Errorf("wrong result with unknown home directory: %s", got)
+	}
+}
@@ -0,0 +1,133 @@
+package paths
+
+import (
+	"net/url"
+	"strings"
+)
+
+// HostPolicy decides how FromURLHost converts the path of a file: URL that
+// refers to a host other than the local host, for a path implementation that
+// has no syntax for paths on other hosts, such as Unix.
+//
+// The function receives the URL's host and its cleaned path, in the syntax
+// of the path implementation, and returns the path that FromURLHost should
+// return. If it returns a URLErrorReason as its error, such as NonLocalHost,
+// FromURLHost wraps it in a *URLError for the URL.
+type HostPolicy func(host, path string) (string, error)
+
+// RejectHosts is a HostPolicy that rejects all URLs for other hosts, with
+// the reason NonLocalHost. This is the behavior of FromURL.
+func RejectHosts(host, path string) (string, error) {
+	return "", NonLocalHost
+}
+
+// IgnoreHosts is a HostPolicy that ignores the host, treating the path of
+// the URL as a path on the local host.
+func IgnoreHosts(host, path string) (string, error) {
+	return path, nil
+}
+
+// Mount describes a remote filesystem that is mounted on the local host, as
+// used by MountPolicy.
+type Mount struct {
+	// Host is the name of the server providing the filesystem, which is
+	// compared case-insensitively with the host of a URL.
+	Host string
+
+	// Remote is the path on the server that is mounted, such as an NFS
+	// export path or "/share" for an SMB share.
+	Remote string
+
+	// Local is the path where the filesystem is mounted on the local host.
+	Local string
+}
+
+// MountPolicy returns a HostPolicy that maps each URL through the given table
+// of mounted filesystems, comparing paths by components using the case rules
+// of the given path implementation.
+//
+// The path of a URL is mapped through the mount whose Host matches the URL's
+// host and whose Remote is the longest prefix of the URL's path, so that,
+// for example, with a mount of server:/export at /mnt/export the URL
+// "file://server/export/a/b" produces the path "/mnt/export/a/b". URLs that
+// match no mount are rejected with the reason NonLocalHost.
+func MountPolicy(p P, mounts []Mount) HostPolicy {
+	return func(host, path string) (string, error) {
+		m, rest := longestMount(mounts, func(m *Mount) ([]string, bool) {
+			if !strings.EqualFold(m.Host, host) {
+				return nil, false
+			}
+			return trimComponents(p, path, m.Remote)
+		})
+		if m == nil {
+			return "", NonLocalHost
+		}
+		return p.Join(append([]string{m.Local}, rest...)...), nil
+	}
+}
+
+// longestMount returns the mount for which the given function returns the
+// fewest remaining path components, and those components, or nil if the
+// function returns false for every mount.
+func longestMount(mounts []Mount, trim func(m *Mount) ([]string, bool)) (*Mount, []string) {
+	var match *Mount
+	var matchRest []string
+	for i := range mounts {
+		m := &mounts[i]
+		rest, ok := trim(m)
+		if !ok || (match != nil && len(rest) >= len(matchRest)) {
+			continue
+		}
+		match, matchRest = m, rest
+	}
+	return match, matchRest
+}
+
+// FromURLHost is a variant of FromURL that also returns the host that a
+// file: URL refers to, or an empty string for a URL that refers to the local
+// host, either with no hostname or with the hostname "localhost".
+//
+// For Windows, a URL with a hostname produces a UNC path, and so for example
+// "file://server/share/a" produces the host "server" and the path
+// `\\server\share\a`. For other implementations, the path of a URL with
+// a hostname is converted using the given policy, which may reject it, ignore
+// the host, or map the path through a table of mounted filesystems. A nil
+// policy is equivalent to RejectHosts.
+//
+// URLs for the local host are converted exactly as for FromURL, after
+// removing any "localhost" hostname.
+func FromURLHost(p P, u *url.URL, policy HostPolicy) (host, path string, err error) {
+	if u.Scheme == "file" && strings.EqualFold(u.Host, "localhost") {
+		local := *u
+		local.Host = ""
+		u = &local
+	}
+	if u.Scheme != "file" || u.Host == "" {
+		path, err := p.FromURL(u)
+		return "", path, err
+	}
+	if u.User != nil {
+		return "", "", &URLError{URL: u, Reason: UserInfo}
+	}
+
+	if syntaxOf(p) == windowsImpl {
+		im := windowsImpl
+		path = p.Clean(`\\` + u.Host + im.fromSlash(u.Path))
+		if !im.isUNC(path) {
+			return "", "", &URLError{URL: u, Reason: MissingShare}
+		}
+		return u.Host, path, nil
+	}
+
+	if policy == nil {
+		policy = RejectHosts
+	}
+	path, err = policy(u.Host, p.Clean(u.Path))
+	if err != nil {
+		if reason, ok := err.(URLErrorReason); ok {
+			err = &URLError{URL: u, Reason: reason}
+		}
+		return "", "", err
+	}
+	return u.Host, path, nil
+}
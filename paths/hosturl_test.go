@@ -0,0 +1,105 @@
+package paths
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestFromURLHost(t *testing.T) {
+	type Test struct {
+		url        string
+		host, path string
+	}
+
+	mounts := MountPolicy(Unix, []Mount{
+		{Host: "nfs1", Remote: "/export", Local: "/mnt/export"},
+		{Host: "nfs1", Remote: "/export/home", Local: "/home"},
+		{Host: "fileserver", Remote: "/share", Local: "/mnt/share"},
+	})
+
+	tests := map[string]struct {
+		p      P
+		policy HostPolicy
+		tests  []Test
+	}{
+		"Unix reject": {
+			Unix,
+			nil,
+			[]Test{
+				{"file:///a/b", "", "/a/b"},
+				{"file://localhost/a/b", "", "/a/b"},
+				{"a/b", "", "a/b"},
+				{"file://server/a/b", "", "NonLocalHost"},
+				{"file://user@server/a/b", "", "UserInfo"},
+				{"http://server/a/b", "", "BadScheme"},
+			},
+		},
+		"Unix ignore": {
+			Unix,
+			IgnoreHosts,
+			[]Test{
+				{"file:///a/b", "", "/a/b"},
+				{"file://server/a/../b", "server", "/b"},
+			},
+		},
+		"Unix mounts": {
+			Unix,
+			mounts,
+			[]Test{
+				{"file://nfs1/export/a", "nfs1", "/mnt/export/a"},
+				{"file://NFS1/export/home/alice", "NFS1", "/home/alice"},
+				{"file://nfs1/export/homes", "nfs1", "/mnt/export/homes"},
+				{"file://nfs1/export", "nfs1", "/mnt/export"},
+				{"file://nfs1/other", "", "NonLocalHost"},
+				{"file://fileserver/share/x%20y", "fileserver", "/mnt/share/x y"},
+				{"file://nfs2/export/a", "", "NonLocalHost"},
+			},
+		},
+		"Windows": {
+			Windows,
+			RejectHosts,
+			[]Test{
+				{"file:///C:/a/b", "", `C:\a\b`},
+				{"file://localhost/C:/a/b", "", `C:\a\b`},
+				{"file://server/share/a", "server", `\\server\share\a`},
+				{"file://server/share", "server", `\\server\share`},
+				{"file://server/", "", "MissingShare"},
+				{"file://user@server/share", "", "UserInfo"},
+			},
+		},
+	}
+	reasons := map[string]URLErrorReason{
+		"NonLocalHost": NonLocalHost,
+		"UserInfo":     UserInfo,
+		"BadScheme":    BadScheme,
+		"MissingShare": MissingShare,
+	}
+
+	for name, implTest := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, test := range implTest.tests {
+				t.Run(test.url, func(t *testing.T) {
+					u, err := url.Parse(test.url)
+					if err != nil {
+						t.Fatal(err)
+					}
+					host, path, err := FromURLHost(implTest.p, u, implTest.policy)
+					if reason, ok := reasons[test.path]; ok {
+						var urlErr *URLError
+						if !errors.As(err, &urlErr) || urlErr.Reason != reason || urlErr.URL == nil {
+							t.Errorf("wrong result for FromURLHost(%q)\ngot:  %q, %q, %v\nwant: %s error", test.url, host, path, err, test.path)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error for FromURLHost(%q): %s", test.url, err)
+					}
+					if host != test.host || path != test.path {
+						t.Errorf("wrong result for FromURLHost(%q)\ngot:  %q, %q\nwant: %q, %q", test.url, host, path, test.host, test.path)
+					}
+				})
+			}
+		})
+	}
+}
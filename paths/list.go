@@ -0,0 +1,47 @@
+package paths
+
+// ListSeparator returns the character that separates the entries of path
+// lists, such as the PATH environment variable, on the target system of the
+// given path implementation: a semicolon for Windows, and a colon otherwise.
+func ListSeparator(p P) byte {
+	return syntaxOf(p).listSeparator()
+}
+
+// SplitList splits a list of paths joined by the list separator of the given
+// path implementation, as is usual for environment variables such as PATH,
+// PYTHONPATH and GOPATH. Unlike strings.Split, SplitList returns an empty
+// slice when passed an empty string.
+//
+// For Windows, an entry may be wrapped in double quotes so that it can
+// contain semicolons, as in "C:\a;b". The quotes are removed in the result.
+// Windows ignores empty entries in path lists, so they are not included.
+//
+// For other implementations empty entries are retained, because Unix
+// systems treat an empty entry as a reference to the current working
+// directory.
+func SplitList(p P, list string) []string {
+	switch im := syntaxOf(p); im {
+	case windowsImpl:
+		return im.windowsSplitList(list)
+	default:
+		return im.unixSplitList(list)
+	}
+}
+
+// JoinList is the inverse of SplitList, joining the given paths using the
+// list separator of the given path implementation.
+//
+// For Windows, entries that contain semicolons are wrapped in double quotes
+// and empty entries are omitted. Returns an error if any entry contains
+// a double quote, because such an entry cannot be represented.
+//
+// For other implementations there is no quoting mechanism, so JoinList
+// returns an error if any entry contains a colon.
+func JoinList(p P, elems ...string) (string, error) {
+	switch im := syntaxOf(p); im {
+	case windowsImpl:
+		return im.windowsJoinList(elems)
+	default:
+		return im.unixJoinList(elems)
+	}
+}
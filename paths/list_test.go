@@ -0,0 +1,126 @@
+package paths
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitList(t *testing.T) {
+	type Test struct {
+		list string
+		want []string
+	}
+
+	implTests := map[string][]Test{
+		"Unix": {
+			{"", []string{}},
+			{"/usr/bin", []string{"/usr/bin"}},
+			{"/usr/local/bin:/usr/bin:/bin", []string{"/usr/local/bin", "/usr/bin", "/bin"}},
+			{"/usr/bin::/bin", []string{"/usr/bin", "", "/bin"}},
+			{":/bin", []string{"", "/bin"}},
+			{"/bin:", []string{"/bin", ""}},
+			{`"/a:b"`, []string{`"/a`, `b"`}},
+		},
+		"Windows": {
+			{"", []string{}},
+			{`C:\Windows`, []string{`C:\Windows`}},
+			{`C:\Windows;C:\Windows\System32`, []string{`C:\Windows`, `C:\Windows\System32`}},
+			{`C:\a;;C:\b;`, []string{`C:\a`, `C:\b`}},
+			{`;C:\a`, []string{`C:\a`}},
+			{`;;`, []string{}},
+			{`"C:\a;b";C:\c`, []string{`C:\a;b`, `C:\c`}},
+			{`"C:\Program Files"`, []string{`C:\Program Files`}},
+			{`C:\a"b;c"d;e`, []string{`C:\ab;cd`, `e`}},
+			{`"";C:\a`, []string{`C:\a`}},
+			{`C:\a:C:\b`, []string{`C:\a:C:\b`}},
+		},
+	}
+
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Windows": Windows,
+	}
+
+	for implName, tests := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			for _, test := range tests {
+				t.Run(test.list, func(t *testing.T) {
+					if got := SplitList(p, test.list); !reflect.DeepEqual(got, test.want) {
+						t.Errorf("wrong result for %s SplitList(%q)\ngot:  %q\nwant: %q", implName, test.list, got, test.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestJoinList(t *testing.T) {
+	type Test struct {
+		elems []string
+		want  string
+	}
+
+	implTests := map[string][]Test{
+		"Unix": {
+			{[]string{}, ""},
+			{[]string{"/usr/bin"}, "/usr/bin"},
+			{[]string{"/usr/bin", "/bin"}, "/usr/bin:/bin"},
+			{[]string{"/usr/bin", "", "/bin"}, "/usr/bin::/bin"},
+			{[]string{"/a;b"}, "/a;b"},
+			{[]string{"/a:b"}, "err"},
+		},
+		"Windows": {
+			{[]string{}, ""},
+			{[]string{`C:\Windows`}, `C:\Windows`},
+			{[]string{`C:\Windows`, `C:\Windows\System32`}, `C:\Windows;C:\Windows\System32`},
+			{[]string{`C:\a`, ``, `C:\b`}, `C:\a;C:\b`},
+			{[]string{`C:\a;b`, `C:\c`}, `"C:\a;b";C:\c`},
+			{[]string{`C:\Program Files`}, `C:\Program Files`},
+			{[]string{`C:\a"b`}, "err"},
+		},
+	}
+
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Windows": Windows,
+	}
+
+	for implName, tests := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			for _, test := range tests {
+				t.Run(test.want, func(t *testing.T) {
+					got, err := JoinList(p, test.elems...)
+					if test.want == "err" {
+						if err == nil {
+							t.Errorf("wrong result for %s JoinList(%q)\ngot:  %s\nwant: an error", implName, test.elems, got)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error for %s JoinList(%q): %s", implName, test.elems, err)
+					}
+					if got != test.want {
+						t.Errorf("wrong result for %s JoinList(%q)\ngot:  %s\nwant: %s", implName, test.elems, got, test.want)
+					}
+
+					// The result should also round-trip through SplitList,
+					// aside from the empty entries that Windows discards.
+					want := test.elems
+					if implName == "Windows" {
+						want = []string{}
+						for _, e := range test.elems {
+							if e != "" {
+								want = append(want, e)
+							}
+						}
+					}
+					if back := SplitList(p, got); !reflect.DeepEqual(back, want) {
+						t.Errorf("%s JoinList result %q does not round-trip\ngot:  %q\nwant: %q", implName, got, back, want)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// ErrNotFound is the error wrapped by the error LookPath returns when it
+// fails to find an executable file.
+var ErrNotFound = errors.New("executable file not found in PATH")
+
+// LookPathEnv describes the parts of a target system that LookPath uses
+// to find an executable file.
+type LookPathEnv struct {
+	// FS is a view of the target system's filesystem, whose root directory
+	// corresponds to the native path given in Root.
+	FS fs.FS
+
+	// Root is the absolute native path where FS is rooted, such as "/" for
+	// an image of a whole Unix filesystem or `C:\` for the C drive of
+	// a Windows system. Executables outside of this directory cannot
+	// be found.
+	Root string
+
+	// Dir is the absolute native path of the working directory, which
+	// relative file names and PATH entries are resolved against. If empty,
+	// the working directory is assumed to be Root.
+	Dir string
+
+	// Path is the value of the PATH environment variable, which is split
+	// using SplitList.
+	Path string
+
+	// PathExt is the value of the PATHEXT environment variable, used only
+	// for Windows. If empty, the Windows default of ".COM;.EXE;.BAT;.CMD"
+	// is used.
+	PathExt string
+
+	// NoCurrentDirectory, used only for Windows, prevents searching the
+	// working directory before the entries in Path, as if the environment
+	// variable NoDefaultCurrentDirectoryInExePath were set.
+	NoCurrentDirectory bool
+}
+
+// LookPath searches for an executable file with the given name on the target
+// system described by env, using the rules of the os/exec package's LookPath
+// function for the target system of the given path implementation.
+//
+// For Windows, a name without a separator or colon is first sought in the
+// working directory unless env.NoCurrentDirectory is set, and then in each
+// entry of the PATH. Each candidate is tried both as given, if it has an
+// extension, and then with each of the extensions in PATHEXT appended.
+// Names are matched case-insensitively against the filesystem, and any
+// file that is not a directory is considered to be executable.
+//
+// For other implementations, a name without a slash is sought in each entry
+// of the PATH, where an empty entry refers to the working directory, and
+// only files with at least one execute permission bit set are considered.
+//
+// The result is the native path that the target system would execute: a
+// PATH entry joined with the file name, or the name itself if it contains
+// a separator. Unlike os/exec, LookPath does not treat results that are
+// relative to the working directory as errors.
+func LookPath(p P, file string, env LookPathEnv) (string, error) {
+	switch syntaxOf(p) {
+	case windowsImpl:
+		return env.windowsLookPath(file)
+	default:
+		return env.unixLookPath(p, file)
+	}
+}
+
+func (env *LookPathEnv) unixLookPath(p P, file string) (string, error) {
+	if strings.Contains(file, "/") {
+		if err := env.unixFindExecutable(p, file); err != nil {
+			return "", fmt.Errorf("%q: %w", file, err)
+		}
+		return file, nil
+	}
+	for _, dir := range SplitList(p, env.Path) {
+		if dir == "" {
+			// Unix shells interpret an empty entry as the current directory
+			dir = "."
+		}
+		path := p.Join(dir, file)
+		if err := env.unixFindExecutable(p, path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%q: %w", file, ErrNotFound)
+}
+
+func (env *LookPathEnv) unixFindExecutable(p P, path string) error {
+	name, err := env.fsName(p, path)
+	if err != nil {
+		return ErrNotFound
+	}
+	info, err := fs.Stat(env.FS, name)
+	if err != nil {
+		return ErrNotFound
+	}
+	mode := info.Mode()
+	if mode.IsDir() || mode&0111 == 0 {
+		return fs.ErrPermission
+	}
+	return nil
+}
+
+func (env *LookPathEnv) windowsLookPath(file string) (string, error) {
+	exts := env.windowsPathExt()
+	if strings.ContainsAny(file, `:\/`) {
+		f, err := env.windowsFindExecutable(file, exts)
+		if err != nil {
+			return "", fmt.Errorf("%q: %w", file, err)
+		}
+		return f, nil
+	}
+
+	if !env.NoCurrentDirectory {
+		if f, err := env.windowsFindExecutable(`.\`+file, exts); err == nil {
+			return f, nil
+		}
+	}
+	for _, dir := range SplitList(Windows, env.Path) {
+		if f, err := env.windowsFindExecutable(Windows.Join(dir, file), exts); err == nil {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%q: %w", file, ErrNotFound)
+}
+
+func (env *LookPathEnv) windowsFindExecutable(path string, exts []string) (string, error) {
+	if windowsHasExt(path) && env.windowsIsFile(path) {
+		return path, nil
+	}
+	for _, ext := range exts {
+		if f := path + ext; env.windowsIsFile(f) {
+			return f, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (env *LookPathEnv) windowsIsFile(path string) bool {
+	name, err := env.fsName(Windows, path)
+	if err != nil {
+		return false
+	}
+	name, err = resolveFold(env.FS, name)
+	if err != nil {
+		return false
+	}
+	info, err := fs.Stat(env.FS, name)
+	return err == nil && !info.IsDir()
+}
+
+func (env *LookPathEnv) windowsPathExt() []string {
+	if env.PathExt == "" {
+		return []string{".com", ".exe", ".bat", ".cmd"}
+	}
+	var exts []string
+	for _, ext := range strings.Split(strings.ToLower(env.PathExt), ";") {
+		if ext == "" {
+			continue
+		}
+		if ext[0] != '.' {
+			ext = "." + ext
+		}
+		exts = append(exts, ext)
+	}
+	return exts
+}
+
+// fsName returns the name within env.FS for the given native path, which
+// may be relative to env.Dir.
+func (env *LookPathEnv) fsName(p P, path string) (string, error) {
+	dir := env.Dir
+	if dir == "" {
+		dir = env.Root
+	}
+	path, err := Abs(p, dir, path)
+	if err != nil {
+		return "", err
+	}
+	return fsName(p, env.Root, path)
+}
+
+// windowsHasExt returns true if the final element of the given Windows path
+// has an extension.
+func windowsHasExt(path string) bool {
+	i := strings.LastIndex(path, ".")
+	if i < 0 {
+		return false
+	}
+	return strings.LastIndexAny(path, `:\/`) < i
+}
@@ -0,0 +1,147 @@
+package paths
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLookPath(t *testing.T) {
+	type Test struct {
+		file, want string
+	}
+
+	unixFS := fstest.MapFS{
+		"usr/bin/ls":         {Mode: 0755},
+		"usr/bin/notexec":    {Mode: 0644},
+		"usr/bin/dir":        {Mode: 0755 | fs.ModeDir},
+		"usr/local/bin/ls":   {Mode: 0755},
+		"usr/local/bin/tool": {Mode: 0700},
+		"home/alice/run.sh":  {Mode: 0755},
+		"home/alice/bin/app": {Mode: 0755},
+		"opt/app/bin/app":    {Mode: 0755},
+	}
+	unixEnv := LookPathEnv{
+		FS:   unixFS,
+		Root: "/",
+		Dir:  "/home/alice",
+		Path: "/usr/local/bin:/usr/bin:bin",
+	}
+	unixTests := []Test{
+		{"ls", "/usr/local/bin/ls"},
+		{"tool", "/usr/local/bin/tool"},
+		{"notexec", "err"},
+		{"dir", "err"},
+		{"app", "bin/app"},
+		{"run.sh", "err"},
+		{"./run.sh", "./run.sh"},
+		{"/opt/app/bin/app", "/opt/app/bin/app"},
+		{"/usr/bin/notexec", "err"},
+		{"LS", "err"},
+		{"missing", "err"},
+	}
+
+	t.Run("Unix", func(t *testing.T) {
+		for _, test := range unixTests {
+			t.Run(test.file, func(t *testing.T) {
+				testLookPath(t, Unix, test.file, unixEnv, test.want)
+			})
+		}
+
+		env := unixEnv
+		env.Path = "/usr/bin::/usr/local/bin"
+		t.Run("empty entry", func(t *testing.T) {
+			testLookPath(t, Unix, "run.sh", env, "run.sh")
+		})
+	})
+
+	windowsFS := fstest.MapFS{
+		"Windows/System32/cmd.exe":        {},
+		"Windows/System32/where.exe":      {},
+		"Windows/System32/drivers":        {Mode: fs.ModeDir | 0755},
+		"Program Files/Git/cmd/git.exe":   {},
+		"Program Files/Git/cmd/gitk.cmd":  {},
+		"Program Files/Git/cmd/gitk.exe":  {},
+		"Program Files/Tool/tool.ps1":     {},
+		"Users/Alice/where.bat":           {},
+		"Users/Alice/script.py":           {},
+		"Users/Alice/Scripts/deploy.bat":  {},
+		"Program Files/Git/cmd/noext":     {},
+		"Program Files/Git/cmd/x.tar.exe": {},
+	}
+	windowsEnv := LookPathEnv{
+		FS:   windowsFS,
+		Root: `C:\`,
+		Dir:  `C:\Users\Alice`,
+		Path: `C:\WINDOWS\system32;;"C:\Program Files\Git\cmd";C:\Program Files\Tool`,
+	}
+	windowsTests := []Test{
+		{`cmd`, `C:\WINDOWS\system32\cmd.exe`},
+		{`CMD.EXE`, `C:\WINDOWS\system32\CMD.EXE`},
+		{`git`, `C:\Program Files\Git\cmd\git.exe`},
+		{`gitk`, `C:\Program Files\Git\cmd\gitk.exe`},
+		{`gitk.cmd`, `C:\Program Files\Git\cmd\gitk.cmd`},
+		{`where`, `.\where.bat`},
+		{`drivers`, `err`},
+		{`tool`, `err`},
+		{`tool.ps1`, `C:\Program Files\Tool\tool.ps1`},
+		{`noext`, `err`},
+		{`x.tar`, `C:\Program Files\Git\cmd\x.tar.exe`},
+		{`script.py`, `.\script.py`},
+		{`Scripts\deploy`, `Scripts\deploy.bat`},
+		{`\Windows\System32\where`, `\Windows\System32\where.exe`},
+		{`c:\windows\system32\where.exe`, `c:\windows\system32\where.exe`},
+		{`D:\tool.exe`, `err`},
+	}
+
+	t.Run("Windows", func(t *testing.T) {
+		for _, test := range windowsTests {
+			t.Run(test.file, func(t *testing.T) {
+				testLookPath(t, Windows, test.file, windowsEnv, test.want)
+			})
+		}
+
+		t.Run("NoCurrentDirectory", func(t *testing.T) {
+			env := windowsEnv
+			env.NoCurrentDirectory = true
+			testLookPath(t, Windows, "where", env, `C:\WINDOWS\system32\where.exe`)
+		})
+		t.Run("PathExt", func(t *testing.T) {
+			env := windowsEnv
+			env.PathExt = ".PS1;CMD"
+			testLookPath(t, Windows, "tool", env, `C:\Program Files\Tool\tool.ps1`)
+			testLookPath(t, Windows, "gitk", env, `C:\Program Files\Git\cmd\gitk.cmd`)
+			testLookPath(t, Windows, "git", env, `err`)
+		})
+	})
+}
+
+func testLookPath(t *testing.T, p P, file string, env LookPathEnv, want string) {
+	t.Helper()
+	got, err := LookPath(p, file, env)
+	if want == "err" {
+		if err == nil {
+			t.Errorf("wrong result for LookPath(%q)\ngot:  %s\nwant: an error", file, got)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error for LookPath(%q): %s", file, err)
+	}
+	if got != want {
+		t.Errorf("wrong result for LookPath(%q)\ngot:  %s\nwant: %s", file, got, want)
+	}
+}
+
+func TestLookPathNotFound(t *testing.T) {
+	env := LookPathEnv{
+		FS:   fstest.MapFS{},
+		Root: "/",
+		Path: "/usr/bin",
+	}
+	_, err := LookPath(Unix, "missing", env)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("error does not wrap ErrNotFound: %v", err)
+	}
+}
@@ -0,0 +1,688 @@
+package paths
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	errNotDir   = errors.New("not a directory")
+	errIsDir    = errors.New("is a directory")
+	errNotEmpty = errors.New("directory not empty")
+	errTooMany  = errors.New("too many levels of symbolic links")
+)
+
+// MemFS is an in-memory filesystem that follows the naming rules of the
+// target system of a particular path implementation, for use in testing
+// code that manipulates files on systems other than the local one.
+//
+// All of the methods of MemFS take absolute native paths in the syntax of
+// the path implementation, and every lookup uses that implementation's
+// rules. For Windows this means that names are case-insensitive but
+// case-preserving, that each drive letter or UNC share is a separate
+// volume, and that files cannot be created with reserved device names such
+// as "CON" or "aux.c", or with names containing characters that Windows
+// does not allow. As in the Win32 API, Windows paths other than those
+// starting with `\\?\` are first normalized in the same way as by
+// WindowsFullPath, and so names that differ only by trailing periods or
+// spaces refer to the same file, and ".." components are resolved before
+// following any symbolic links. A path starting with `\\?\` refers to the
+// same file as the path that follows the prefix, as in `\\?\C:\a` for
+// `C:\a` and `\\?\UNC\server\share\a` for `\\server\share\a`, but
+// without that normalization. For Darwin, names are also insensitive to
+// differences in Unicode normalization form.
+//
+// MemFS is safe for concurrent use.
+type MemFS struct {
+	p  P
+	mu sync.RWMutex
+
+	// volumes has the root directory of each volume, keyed by the wordKey
+	// of its volume name. Unix and Darwin have only one volume, whose
+	// name is the empty string.
+	volumes map[string]*memNode
+}
+
+// memNode is a file, directory, or symbolic link in a MemFS.
+type memNode struct {
+	name    string
+	mode    fs.FileMode
+	data    []byte // file content, or symbolic link destination
+	modTime time.Time
+
+	// children is non-nil only for a directory, and is keyed by the
+	// wordKey of each child's name.
+	children map[string]*memNode
+}
+
+// NewMemFS returns a new MemFS that uses the given path implementation.
+//
+// The new filesystem contains only an empty root directory: "/" for Unix and
+// Darwin, or `C:\` for Windows. Additional Windows volumes can be created
+// using MkdirAll.
+func NewMemFS(p P) *MemFS {
+	m := &MemFS{
+		p:       p,
+		volumes: make(map[string]*memNode),
+	}
+	if syntaxOf(p) == windowsImpl {
+		m.volumes[m.volumeKey("C:")] = newMemDir("C:", 0777)
+	} else {
+		m.volumes[""] = newMemDir("/", 0777)
+	}
+	return m
+}
+
+func newMemDir(name string, perm fs.FileMode) *memNode {
+	return &memNode{
+		name:     name,
+		mode:     fs.ModeDir | perm.Perm(),
+		modTime:  time.Now(),
+		children: make(map[string]*memNode),
+	}
+}
+
+// Mkdir creates a new directory with the given path and permission bits.
+// The parent directory must already exist.
+func (m *MemFS) Mkdir(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdir(path, perm)
+}
+
+func (m *MemFS) mkdir(path string, perm fs.FileMode) error {
+	r, err := m.resolve(path, false)
+	if err != nil {
+		return &fs.PathError{Op: "mkdir", Path: path, Err: err}
+	}
+	if r.node != nil {
+		return &fs.PathError{Op: "mkdir", Path: path, Err: fs.ErrExist}
+	}
+	if err := m.validName(r.name); err != nil {
+		return &fs.PathError{Op: "mkdir", Path: path, Err: err}
+	}
+	r.dir().children[m.key(r.name)] = newMemDir(r.name, perm)
+	return nil
+}
+
+// MkdirAll creates the directory with the given path along with any of its
+// parents that don't already exist, including its volume.
+func (m *MemFS) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdirAll(path, perm)
+}
+
+func (m *MemFS) mkdirAll(path string, perm fs.FileMode) error {
+	r, err := m.resolve(path, true)
+	switch {
+	case err == nil && r.node != nil:
+		if !r.node.mode.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: path, Err: errNotDir}
+		}
+		return nil
+	case err == nil:
+		return m.mkdir(path, perm)
+	case !errors.Is(err, fs.ErrNotExist):
+		return &fs.PathError{Op: "mkdir", Path: path, Err: err}
+	}
+
+	root, _ := splitRoot(m.p, path)
+	if m.p.Clean(root) == m.p.Clean(path) {
+		// The volume itself doesn't exist, so we'll create it.
+		vol := m.p.VolumeName(path)
+		m.volumes[m.volumeKey(vol)] = newMemDir(vol, perm)
+		return nil
+	}
+	if err := m.mkdirAll(m.p.Dir(path), perm); err != nil {
+		return err
+	}
+	return m.mkdir(path, perm)
+}
+
+// WriteFile writes the given data to the file with the given path, creating
+// it with the given permission bits if necessary. If the path refers to
+// a symbolic link then its destination is written.
+func (m *MemFS) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, err := m.resolve(path, true)
+	if err != nil {
+		return &fs.PathError{Op: "open", Path: path, Err: err}
+	}
+	if r.node != nil {
+		if r.node.mode.IsDir() {
+			return &fs.PathError{Op: "open", Path: path, Err: errIsDir}
+		}
+		r.node.data = append([]byte(nil), data...)
+		r.node.modTime = time.Now()
+		return nil
+	}
+	if err := m.validName(r.name); err != nil {
+		return &fs.PathError{Op: "open", Path: path, Err: err}
+	}
+	r.dir().children[m.key(r.name)] = &memNode{
+		name:    r.name,
+		mode:    perm.Perm(),
+		data:    append([]byte(nil), data...),
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+// ReadFile returns the content of the file with the given path.
+func (m *MemFS) ReadFile(path string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, err := m.lookup(path, true)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: err}
+	}
+	if node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: path, Err: errIsDir}
+	}
+	return append([]byte(nil), node.data...), nil
+}
+
+// Symlink creates a symbolic link at the given path whose destination is
+// target, which is a native path that is relative to the directory containing
+// the link unless it is absolute.
+func (m *MemFS) Symlink(target, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, err := m.resolve(path, false)
+	if err != nil {
+		return &fs.PathError{Op: "symlink", Path: path, Err: err}
+	}
+	if r.node != nil {
+		return &fs.PathError{Op: "symlink", Path: path, Err: fs.ErrExist}
+	}
+	if err := m.validName(r.name); err != nil {
+		return &fs.PathError{Op: "symlink", Path: path, Err: err}
+	}
+	r.dir().children[m.key(r.name)] = &memNode{
+		name:    r.name,
+		mode:    fs.ModeSymlink | 0777,
+		data:    []byte(target),
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+// Readlink returns the destination of the symbolic link with the given path.
+func (m *MemFS) Readlink(path string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, err := m.lookup(path, false)
+	if err != nil {
+		return "", &fs.PathError{Op: "readlink", Path: path, Err: err}
+	}
+	if node.mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: path, Err: fs.ErrInvalid}
+	}
+	return string(node.data), nil
+}
+
+// Remove removes the file, symbolic link, or empty directory with the
+// given path.
+func (m *MemFS) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, err := m.resolve(path, false)
+	if err == nil && r.node == nil {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: path, Err: err}
+	}
+	if len(r.dirs) == 0 {
+		// Can't remove the root directory of a volume
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrPermission}
+	}
+	if len(r.node.children) != 0 {
+		return &fs.PathError{Op: "remove", Path: path, Err: errNotEmpty}
+	}
+	delete(r.dir().children, m.key(r.node.name))
+	return nil
+}
+
+// Rename moves the file, symbolic link, or directory at oldpath to newpath,
+// replacing any existing file at newpath.
+//
+// If the two paths refer to the same file, as is possible when names are
+// case-insensitive, Rename changes the case of the file's name.
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	linkErr := func(err error) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+
+	from, err := m.resolve(oldpath, false)
+	if err == nil && from.node == nil {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		return linkErr(err)
+	}
+	if len(from.dirs) == 0 {
+		return linkErr(fs.ErrPermission)
+	}
+	to, err := m.resolve(newpath, false)
+	if err != nil {
+		return linkErr(err)
+	}
+	if len(to.dirs) == 0 {
+		return linkErr(fs.ErrPermission)
+	}
+	if err := m.validName(to.name); err != nil {
+		return linkErr(err)
+	}
+	for _, dir := range to.dirs {
+		if dir == from.node {
+			// Can't move a directory into itself
+			return linkErr(fs.ErrInvalid)
+		}
+	}
+
+	node := from.node
+	switch existing := to.node; {
+	case existing == node:
+		// Renaming a file to itself, possibly with a different case.
+	case existing == nil:
+	case existing.mode.IsDir() && !node.mode.IsDir():
+		return linkErr(errIsDir)
+	case !existing.mode.IsDir() && node.mode.IsDir():
+		return linkErr(errNotDir)
+	case len(existing.children) != 0:
+		return linkErr(errNotEmpty)
+	}
+
+	delete(from.dir().children, m.key(node.name))
+	node.name = to.name
+	to.dir().children[m.key(to.name)] = node
+	return nil
+}
+
+// Stat returns information about the file with the given path, following
+// any symbolic links.
+func (m *MemFS) Stat(path string) (fs.FileInfo, error) {
+	return m.stat("stat", path, true)
+}
+
+// Lstat returns information about the file with the given path. If the path
+// refers to a symbolic link then the result describes the link itself.
+func (m *MemFS) Lstat(path string) (fs.FileInfo, error) {
+	return m.stat("lstat", path, false)
+}
+
+func (m *MemFS) stat(op, path string, follow bool) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, err := m.lookup(path, follow)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: path, Err: err}
+	}
+	return node.info(), nil
+}
+
+// ReadDir returns the entries of the directory with the given path, sorted
+// by name.
+func (m *MemFS) ReadDir(path string) ([]fs.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, err := m.lookup(path, true)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: err}
+	}
+	if !node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: path, Err: errNotDir}
+	}
+	return node.entries(), nil
+}
+
+// FS returns a view of the directory with the given native path as an
+// fs.FS, whose names are translated into native paths under root.
+//
+// The result also implements fs.StatFS, fs.ReadDirFS, fs.ReadFileFS and
+// ReadLinkFS. Symbolic links within it may refer to files outside of root.
+func (m *MemFS) FS(root string) fs.FS {
+	return memView{m: m, root: root}
+}
+
+// memResolved is the result of resolving a path in a MemFS.
+type memResolved struct {
+	// dirs is the chain of directories from the volume root to the
+	// directory containing the file, which is empty if the file is
+	// a volume root.
+	dirs []*memNode
+
+	// name is the name of the file within its directory, as given in
+	// the path rather than as stored in the directory.
+	name string
+
+	// node is the file itself, or nil if it doesn't exist.
+	node *memNode
+}
+
+func (r *memResolved) dir() *memNode {
+	return r.dirs[len(r.dirs)-1]
+}
+
+// lookup returns the node for the file at the given path, or an error if
+// there is no such file.
+func (m *MemFS) lookup(path string, follow bool) (*memNode, error) {
+	r, err := m.resolve(path, follow)
+	if err != nil {
+		return nil, err
+	}
+	if r.node == nil {
+		return nil, fs.ErrNotExist
+	}
+	return r.node, nil
+}
+
+// resolve walks the given path one component at a time, following symbolic
+// links in all but the final component, and following a symbolic link in
+// the final component too if follow is set.
+//
+// If all but the final component exists then the result is successful but
+// its node is nil.
+func (m *MemFS) resolve(path string, follow bool) (*memResolved, error) {
+	if !m.p.IsAbs(path) {
+		return nil, fs.ErrInvalid
+	}
+	im := syntaxOf(m.p)
+	if im == windowsImpl {
+		path = im.fromSlash(path)
+		if long := windowsTrimLongPrefix(path); long != path {
+			path = long
+			if m.p.VolumeName(path) == "" {
+				return nil, fs.ErrNotExist
+			}
+		} else {
+			path = windowsNormalizeFull(path)
+		}
+	}
+	root, rest := splitRoot(m.p, path)
+	vol := m.p.VolumeName(root)
+	stack := []*memNode{m.volumes[m.volumeKey(vol)]}
+	if stack[0] == nil {
+		return nil, fs.ErrNotExist
+	}
+
+	links := 0
+	for {
+		// Take the next component from the front of rest
+		i := 0
+		for i < len(rest) && !im.isPathSeparator(rest[i]) {
+			i++
+		}
+		elem := rest[:i]
+		for i < len(rest) && im.isPathSeparator(rest[i]) {
+			i++
+		}
+		rest = rest[i:]
+		final := rest == ""
+
+		switch elem {
+		case "", ".", "..":
+			if elem == ".." && len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+			if !final {
+				continue
+			}
+			// The path refers to a directory we already visited.
+			node := stack[len(stack)-1]
+			return &memResolved{dirs: stack[:len(stack)-1], name: node.name, node: node}, nil
+		}
+
+		dir := stack[len(stack)-1]
+		if !dir.mode.IsDir() {
+			return nil, errNotDir
+		}
+		node := dir.children[m.key(elem)]
+		if node == nil {
+			if final {
+				return &memResolved{dirs: stack, name: elem}, nil
+			}
+			return nil, fs.ErrNotExist
+		}
+		if node.mode&fs.ModeSymlink == 0 || (final && !follow) {
+			if final {
+				return &memResolved{dirs: stack, name: elem, node: node}, nil
+			}
+			stack = append(stack, node)
+			continue
+		}
+
+		links++
+		if links > maxSymlinks {
+			return nil, errTooMany
+		}
+		target := string(node.data)
+		targetRoot, targetRest := splitRoot(m.p, target)
+		if targetRoot != "" {
+			if targetVol := m.p.VolumeName(targetRoot); targetVol != "" {
+				vol = targetVol
+			}
+			stack = []*memNode{m.volumes[m.volumeKey(vol)]}
+			if stack[0] == nil {
+				return nil, fs.ErrNotExist
+			}
+		}
+		if rest != "" {
+			targetRest += string(im.separator()) + rest
+		}
+		rest = targetRest
+		if rest == "" {
+			// The link refers to the root of a volume
+			rest = "."
+		}
+	}
+}
+
+// validName returns an error if the given name cannot be used for a new
+// file in this filesystem.
+func (m *MemFS) validName(name string) error {
+	if !validFileName(m.p, name) {
+		return fs.ErrInvalid
+	}
+	return nil
+}
+
+func (m *MemFS) key(name string) string {
+	return wordKey(m.p, name)
+}
+
+func (m *MemFS) volumeKey(vol string) string {
+	return wordKey(m.p, syntaxOf(m.p).fromSlash(vol))
+}
+
+func (n *memNode) info() fs.FileInfo {
+	return memFileInfo{
+		name:    n.name,
+		size:    int64(len(n.data)),
+		mode:    n.mode,
+		modTime: n.modTime,
+	}
+}
+
+func (n *memNode) entries() []fs.DirEntry {
+	entries := make([]fs.DirEntry, 0, len(n.children))
+	for _, child := range n.children {
+		entries = append(entries, fs.FileInfoToDirEntry(child.info()))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries
+}
+
+type memFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i memFileInfo) ModTime() time.Time { return i.modTime }
+func (i memFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memFileInfo) Sys() interface{}   { return nil }
+
+// memView is the fs.FS view of a MemFS returned by MemFS.FS.
+type memView struct {
+	m    *MemFS
+	root string
+}
+
+var _ fs.StatFS = memView{}
+var _ fs.ReadDirFS = memView{}
+var _ fs.ReadFileFS = memView{}
+var _ ReadLinkFS = memView{}
+
+func (v memView) path(op, name string) (string, error) {
+	// A backslash in a name would be a separator in a Windows path, so
+	// Windows names cannot contain one. Slashes separate io/fs names and so
+	// are already handled by nativeName.
+	if !fs.ValidPath(name) || (syntaxOf(v.m.p) == windowsImpl && strings.ContainsRune(name, '\\')) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return nativeName(v.m.p, v.root, name), nil
+}
+
+// pathError rewrites an error returned by MemFS to refer to the given
+// io/fs name, rather than to the native path.
+func (v memView) pathError(op, name string, err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return &fs.PathError{Op: op, Path: name, Err: pathErr.Err}
+	}
+	return err
+}
+
+func (v memView) Open(name string) (fs.File, error) {
+	path, err := v.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	v.m.mu.RLock()
+	defer v.m.mu.RUnlock()
+	node, err := v.m.lookup(path, true)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	f := &memFile{info: node.info()}
+	if node.mode.IsDir() {
+		f.entries = node.entries()
+	} else {
+		f.r = bytes.NewReader(append([]byte(nil), node.data...))
+	}
+	return f, nil
+}
+
+func (v memView) Stat(name string) (fs.FileInfo, error) {
+	path, err := v.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := v.m.Stat(path)
+	return info, v.pathError("stat", name, err)
+}
+
+func (v memView) Lstat(name string) (fs.FileInfo, error) {
+	path, err := v.path("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := v.m.Lstat(path)
+	return info, v.pathError("lstat", name, err)
+}
+
+func (v memView) ReadLink(name string) (string, error) {
+	path, err := v.path("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	target, err := v.m.Readlink(path)
+	return target, v.pathError("readlink", name, err)
+}
+
+func (v memView) ReadDir(name string) ([]fs.DirEntry, error) {
+	path, err := v.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := v.m.ReadDir(path)
+	return entries, v.pathError("readdir", name, err)
+}
+
+func (v memView) ReadFile(name string) ([]byte, error) {
+	path, err := v.path("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := v.m.ReadFile(path)
+	return data, v.pathError("readfile", name, err)
+}
+
+// memFile is an open file or directory from a memView.
+type memFile struct {
+	info    fs.FileInfo
+	r       *bytes.Reader
+	entries []fs.DirEntry
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	if f.r == nil {
+		return 0, &fs.PathError{Op: "read", Path: f.info.Name(), Err: errIsDir}
+	}
+	return f.r.Read(p)
+}
+
+func (f *memFile) Close() error {
+	return nil
+}
+
+func (f *memFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if f.r != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: f.info.Name(), Err: errNotDir}
+	}
+	if n <= 0 {
+		entries := f.entries
+		f.entries = nil
+		return entries, nil
+	}
+	if len(f.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(f.entries) {
+		n = len(f.entries)
+	}
+	entries := f.entries[:n]
+	f.entries = f.entries[n:]
+	return entries, nil
+}
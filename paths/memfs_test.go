@@ -0,0 +1,320 @@
+package paths
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMemFS(t *testing.T) {
+	type Test struct {
+		write, read string
+		want        string
+	}
+
+	implTests := map[string]struct {
+		p     P
+		dir   string
+		tests []Test
+	}{
+		"Unix": {
+			Unix,
+			"/home/alice",
+			[]Test{
+				{"/home/alice/a.txt", "/home/alice/a.txt", "ok"},
+				{"/home/alice/a.txt", "/home/alice/A.TXT", "err"},
+				{"/home/alice/CON", "/home/alice/CON", "ok"},
+				{"/home/alice/x:y", "/home/alice/x:y", "ok"},
+				{"/home/alice/caf\u00e9", "/home/alice/cafe\u0301", "err"},
+				{"/home/alice/b.txt", "/home/alice/../alice/./b.txt", "ok"},
+				{"/home/alice/..", "", "err"},
+				{"relative.txt", "", "err"},
+			},
+		},
+		"Darwin": {
+			Darwin,
+			"/Users/alice",
+			[]Test{
+				{"/Users/alice/a.txt", "/Users/alice/a.txt", "ok"},
+				{"/Users/alice/a.txt", "/users/ALICE/A.TXT", "ok"},
+				{"/Users/alice/caf\u00e9", "/Users/alice/cafe\u0301", "ok"},
+				{"/Users/alice/CAFE\u0301", "/Users/alice/caf\u00e9", "ok"},
+				{"/Users/alice/CON", "/Users/alice/con", "ok"},
+			},
+		},
+		"Windows": {
+			Windows,
+			`C:\Users\Alice`,
+			[]Test{
+				{`C:\Users\Alice\a.txt`, `C:\Users\Alice\a.txt`, "ok"},
+				{`C:\Users\Alice\a.txt`, `c:/USERS/alice/A.TXT`, "ok"},
+				{"C:\\Users\\Alice\\caf\u00e9", "C:\\Users\\Alice\\CAF\u00c9", "ok"},
+				{"C:\\Users\\Alice\\caf\u00e9", "C:\\Users\\Alice\\cafe\u0301", "err"},
+				{`\\server\share\a.txt`, `\\SERVER\Share\A.txt`, "ok"},
+				{`\\server\share\a.txt`, `\\server\other\a.txt`, "err"},
+				{`D:\a.txt`, `d:\A.TXT`, "ok"},
+				{`D:\a.txt`, `C:\a.txt`, "err"},
+				{`C:\Users\Alice\CON`, "", "err"},
+				{`C:\Users\Alice\aux.c`, "", "err"},
+				{`C:\Users\Alice\com1.txt`, "", "err"},
+				{`C:\Users\Alice\x:y`, "", "err"},
+				{`C:\Users\Alice\what?`, "", "err"},
+				{`C:\Users\Alice\trailing.`, `C:\Users\Alice\TRAILING`, "ok"},
+				{`C:\Users\Alice\trailing. .`, `C:\Users\Alice\trailing `, "ok"},
+				{`C:\Users\Alice\dir.\a.txt`, `C:\Users\Alice\dir\a.txt`, "ok"},
+				{`C:\Users\Alice\console`, `C:\Users\Alice\console`, "ok"},
+				{`C:\Users\Alice\b.txt`, `\\?\C:\Users\Alice\b.txt`, "ok"},
+				{`\\?\C:\Users\Alice\c.txt`, `c:\users\alice\C.TXT`, "ok"},
+				{`\\server\share\b.txt`, `\\?\UNC\server\share\b.txt`, "ok"},
+				{`C:\Users\Alice\d.txt`, `\\?\C:\Users\Alice\d.txt.`, "err"},
+				{`C:\Users\Alice\e.txt`, `\\?\Volume{0}\e.txt`, "err"},
+				{`Users\Alice\a.txt`, "", "err"},
+			},
+		},
+	}
+
+	for implName, implTest := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			for _, test := range implTest.tests {
+				t.Run(test.write+" "+test.read, func(t *testing.T) {
+					m := NewMemFS(implTest.p)
+					if err := m.MkdirAll(implTest.dir, 0755); err != nil {
+						t.Fatalf("unexpected error from MkdirAll: %s", err)
+					}
+					if implTest.p.IsAbs(test.write) {
+						if err := m.MkdirAll(implTest.p.Dir(test.write), 0755); err != nil {
+							t.Fatalf("unexpected error from MkdirAll: %s", err)
+						}
+					}
+					err := m.WriteFile(test.write, []byte("data"), 0644)
+					if test.read == "" {
+						if err == nil {
+							t.Errorf("wrong result for WriteFile(%q)\ngot:  success\nwant: an error", test.write)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error from WriteFile(%q): %s", test.write, err)
+					}
+
+					got, err := m.ReadFile(test.read)
+					if test.want == "err" {
+						if err == nil {
+							t.Errorf("wrong result for ReadFile(%q)\ngot:  %s\nwant: an error", test.read, got)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error from ReadFile(%q): %s", test.read, err)
+					}
+					if string(got) != "data" {
+						t.Errorf("wrong result for ReadFile(%q)\ngot:  %s\nwant: data", test.read, got)
+					}
+
+					// The name must be preserved as it was written, apart from
+					// the trailing periods and spaces that Windows removes.
+					name := implTest.p.Base(test.write)
+					if implTest.p == Windows {
+						name = Windows.Base(windowsNormalizeFull(test.write))
+					}
+					entries, err := m.ReadDir(implTest.p.Dir(test.read))
+					if err != nil {
+						t.Fatalf("unexpected error from ReadDir: %s", err)
+					}
+					found := false
+					for _, entry := range entries {
+						if entry.Name() == name {
+							found = true
+						}
+					}
+					if !found {
+						t.Errorf("ReadDir result does not include %q", name)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestMemFSRename(t *testing.T) {
+	m := NewMemFS(Windows)
+	if err := m.MkdirAll(`C:\dir\sub`, 0755); err != nil {
+		t.Fatalf("unexpected error from MkdirAll: %s", err)
+	}
+	if err := m.WriteFile(`C:\dir\readme.txt`, []byte("a"), 0644); err != nil {
+		t.Fatalf("unexpected error from WriteFile: %s", err)
+	}
+
+	// Renaming to a different case changes the stored name
+	if err := m.Rename(`C:\dir\readme.txt`, `C:\DIR\README.TXT`); err != nil {
+		t.Fatalf("unexpected error from Rename: %s", err)
+	}
+	info, err := m.Stat(`C:\dir\readme.txt`)
+	if err != nil {
+		t.Fatalf("unexpected error from Stat: %s", err)
+	}
+	if got, want := info.Name(), "README.TXT"; got != want {
+		t.Errorf("wrong name after case-only rename\ngot:  %s\nwant: %s", got, want)
+	}
+
+	if err := m.Rename(`C:\dir\readme.txt`, `C:\dir\sub\moved.txt`); err != nil {
+		t.Fatalf("unexpected error from Rename: %s", err)
+	}
+	if _, err := m.Stat(`C:\dir\readme.txt`); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("wrong error for renamed file: %v", err)
+	}
+	if _, err := m.ReadFile(`C:\dir\sub\MOVED.txt`); err != nil {
+		t.Errorf("unexpected error reading moved file: %s", err)
+	}
+
+	if err := m.Rename(`C:\dir`, `C:\dir\sub\dir`); err == nil {
+		t.Errorf("no error when moving a directory into itself")
+	}
+	if err := m.Rename(`C:\dir\sub\moved.txt`, `C:\dir\aux`); err == nil {
+		t.Errorf("no error when renaming to a reserved name")
+	}
+	if err := m.Remove(`C:\dir`); err == nil {
+		t.Errorf("no error when removing a non-empty directory")
+	}
+	if err := m.Remove(`C:\dir\sub\moved.txt`); err != nil {
+		t.Errorf("unexpected error from Remove: %s", err)
+	}
+	if err := m.Remove(`C:\dir\SUB`); err != nil {
+		t.Errorf("unexpected error from Remove: %s", err)
+	}
+	if err := m.Remove(`C:\`); err == nil {
+		t.Errorf("no error when removing a volume root")
+	}
+}
+
+func TestMemFSSymlink(t *testing.T) {
+	t.Run("Unix", func(t *testing.T) {
+		m := NewMemFS(Unix)
+		if err := m.MkdirAll("/usr/lib", 0755); err != nil {
+			t.Fatalf("unexpected error from MkdirAll: %s", err)
+		}
+		if err := m.WriteFile("/usr/lib/libc.so", []byte("libc"), 0644); err != nil {
+			t.Fatalf("unexpected error from WriteFile: %s", err)
+		}
+		links := map[string]string{
+			"/lib":           "/usr/lib",
+			"/usr/lib64":     "lib",
+			"/usr/lib/up":    "..",
+			"/usr/lib/loop1": "loop2",
+			"/usr/lib/loop2": "loop1",
+		}
+		for path, target := range links {
+			if err := m.Symlink(target, path); err != nil {
+				t.Fatalf("unexpected error from Symlink: %s", err)
+			}
+		}
+
+		for _, path := range []string{"/lib/libc.so", "/usr/lib64/libc.so", "/usr/lib/up/lib/libc.so", "/lib/up/lib64/../lib/libc.so"} {
+			if _, err := m.ReadFile(path); err != nil {
+				t.Errorf("unexpected error from ReadFile(%q): %s", path, err)
+			}
+		}
+		if _, err := m.ReadFile("/usr/lib/loop1"); err == nil {
+			t.Errorf("no error when reading a symlink loop")
+		}
+		info, err := m.Lstat("/lib")
+		if err != nil {
+			t.Fatalf("unexpected error from Lstat: %s", err)
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			t.Errorf("Lstat result is not a symlink")
+		}
+		target, err := m.Readlink("/lib")
+		if err != nil {
+			t.Fatalf("unexpected error from Readlink: %s", err)
+		}
+		if got, want := target, "/usr/lib"; got != want {
+			t.Errorf("wrong result from Readlink\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+	t.Run("Windows", func(t *testing.T) {
+		m := NewMemFS(Windows)
+		if err := m.MkdirAll(`\\server\share\tools`, 0755); err != nil {
+			t.Fatalf("unexpected error from MkdirAll: %s", err)
+		}
+		if err := m.WriteFile(`\\server\share\tools\cc.exe`, []byte("cc"), 0644); err != nil {
+			t.Fatalf("unexpected error from WriteFile: %s", err)
+		}
+		if err := m.Symlink(`\\SERVER\share\Tools`, `C:\tools`); err != nil {
+			t.Fatalf("unexpected error from Symlink: %s", err)
+		}
+		if err := m.Symlink(`\tools\CC.EXE`, `C:\cc.exe`); err != nil {
+			t.Fatalf("unexpected error from Symlink: %s", err)
+		}
+		for _, path := range []string{`C:\tools\cc.exe`, `c:\CC.exe`} {
+			if _, err := m.ReadFile(path); err != nil {
+				t.Errorf("unexpected error from ReadFile(%q): %s", path, err)
+			}
+		}
+	})
+}
+
+func TestMemFSView(t *testing.T) {
+	m := NewMemFS(Windows)
+	if err := m.MkdirAll(`C:\data\Logs`, 0755); err != nil {
+		t.Fatalf("unexpected error from MkdirAll: %s", err)
+	}
+	if err := m.WriteFile(`C:\data\Logs\Today.log`, []byte("today"), 0644); err != nil {
+		t.Fatalf("unexpected error from WriteFile: %s", err)
+	}
+	if err := m.WriteFile(`C:\data\a.txt`, []byte("a"), 0644); err != nil {
+		t.Fatalf("unexpected error from WriteFile: %s", err)
+	}
+
+	fsys := m.FS(`C:\data`)
+	if err := fstest.TestFS(fsys, "a.txt", "Logs/Today.log"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := fs.ReadFile(fsys, "logs/today.log")
+	if err != nil {
+		t.Fatalf("unexpected error from ReadFile: %s", err)
+	}
+	if got, want := string(data), "today"; got != want {
+		t.Errorf("wrong file content\ngot:  %s\nwant: %s", got, want)
+	}
+	_, err = fs.Stat(fsys, "missing")
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "missing" || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("wrong error for missing file: %v", err)
+	}
+	if _, err := fs.Stat(fsys, `Logs\Today.log`); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("wrong error for name containing a backslash: %v", err)
+	}
+
+	for _, p := range []P{Unix, Darwin} {
+		m := NewMemFS(p)
+		if err := m.MkdirAll("/srv/data/logs", 0755); err != nil {
+			t.Fatalf("unexpected error from MkdirAll: %s", err)
+		}
+		if err := m.WriteFile("/srv/data/logs/a.txt", []byte("a"), 0644); err != nil {
+			t.Fatalf("unexpected error from WriteFile: %s", err)
+		}
+		if err := m.WriteFile(`/srv/data/logs/back\slash`, []byte("b"), 0644); err != nil {
+			t.Fatalf("unexpected error from WriteFile: %s", err)
+		}
+
+		fsys := m.FS("/srv/data")
+		for name, want := range map[string]string{
+			"logs/a.txt":      "a",
+			`logs/back\slash`: "b",
+		} {
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				t.Fatalf("unexpected error from ReadFile(%q): %s", name, err)
+			}
+			if got := string(data); got != want {
+				t.Errorf("wrong content for %q\ngot:  %s\nwant: %s", name, got, want)
+			}
+		}
+		entries, err := fs.ReadDir(fsys, "logs")
+		if err != nil || len(entries) != 2 {
+			t.Errorf("wrong result from ReadDir\ngot:  %v, %v\nwant: 2 entries", entries, err)
+		}
+	}
+}
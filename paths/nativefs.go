@@ -0,0 +1,95 @@
+package paths
+
+import (
+	"io/fs"
+)
+
+// NativeFS wraps an fs.FS so that its files can be accessed using native
+// paths written in the syntax of a particular path implementation.
+//
+// The root directory "." of the wrapped filesystem is taken to correspond to
+// the native path Root, which is the "mount point" of the filesystem. Each
+// native path is converted to a name in the wrapped filesystem by making it
+// relative to Root, and so any path that is not within Root is rejected
+// with an error wrapping fs.ErrInvalid. A relative native path is resolved
+// against Root, as if Root were the working directory passed to Abs.
+//
+// For example, a NativeFS with the Windows implementation and the Root
+// `C:\data` accepts the path `C:\data\logs\a.txt`, or equivalently
+// `logs\a.txt`, and opens the file named "logs/a.txt" in the wrapped
+// filesystem.
+type NativeFS struct {
+	// P is the path implementation whose syntax the native paths use.
+	P P
+
+	// Root is the native path that corresponds to the root of FS.
+	Root string
+
+	// FS is the wrapped filesystem.
+	FS fs.FS
+
+	// FoldCase, if set, causes names that don't exactly match a file in FS
+	// to be matched case-insensitively against the entries of their parent
+	// directories, as Windows does. This requires reading the directories
+	// and so is slower than an exact match.
+	FoldCase bool
+}
+
+// Name returns the name in the wrapped filesystem that corresponds to the
+// given native path. If FoldCase is set then the name is the one matching
+// case-insensitively, if the file exists.
+func (fsys *NativeFS) Name(path string) (string, error) {
+	if abs, err := Abs(fsys.P, fsys.Root, path); err == nil {
+		path = abs
+	} else if !fsys.P.IsAbs(path) {
+		// Root is not absolute, so we can only join the two.
+		path = fsys.P.Join(fsys.Root, path)
+	}
+	name, err := fsName(fsys.P, fsys.Root, path)
+	if err != nil {
+		return "", &fs.PathError{Op: "open", Path: path, Err: fs.ErrInvalid}
+	}
+	if fsys.FoldCase {
+		if folded, err := resolveFold(fsys.FS, name); err == nil {
+			name = folded
+		}
+	}
+	return name, nil
+}
+
+// Open opens the file at the given native path, as with fs.FS.Open.
+func (fsys *NativeFS) Open(path string) (fs.File, error) {
+	name, err := fsys.Name(path)
+	if err != nil {
+		return nil, err
+	}
+	return fsys.FS.Open(name)
+}
+
+// Stat returns information about the file at the given native path, as with
+// fs.Stat.
+func (fsys *NativeFS) Stat(path string) (fs.FileInfo, error) {
+	name, err := fsys.Name(path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(fsys.FS, name)
+}
+
+// ReadDir reads the directory at the given native path, as with fs.ReadDir.
+func (fsys *NativeFS) ReadDir(path string) ([]fs.DirEntry, error) {
+	name, err := fsys.Name(path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(fsys.FS, name)
+}
+
+// ReadFile reads the file at the given native path, as with fs.ReadFile.
+func (fsys *NativeFS) ReadFile(path string) ([]byte, error) {
+	name, err := fsys.Name(path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(fsys.FS, name)
+}
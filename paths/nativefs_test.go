@@ -0,0 +1,130 @@
+package paths
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNativeFS(t *testing.T) {
+	type Test struct {
+		path, want string
+	}
+
+	mapFS := fstest.MapFS{
+		"a.txt":          {Data: []byte("a")},
+		"Logs/Today.log": {Data: []byte("today")},
+	}
+
+	implTests := map[string]struct {
+		fsys  *NativeFS
+		tests []Test
+	}{
+		"Unix": {
+			&NativeFS{P: Unix, Root: "/srv/data", FS: mapFS},
+			[]Test{
+				{"/srv/data", "."},
+				{"/srv/data/a.txt", "a.txt"},
+				{"/srv//data/./Logs/Today.log", "Logs/Today.log"},
+				{"a.txt", "a.txt"},
+				{"Logs/../a.txt", "a.txt"},
+				{"logs/today.log", "logs/today.log"},
+				{"/srv/other/a.txt", "err"},
+				{"/srv/data/../other", "err"},
+				{"../a.txt", "err"},
+				{"/srv/database", "err"},
+			},
+		},
+		"Windows": {
+			&NativeFS{P: Windows, Root: `C:\data`, FS: mapFS},
+			[]Test{
+				{`C:\data`, "."},
+				{`C:\data\a.txt`, "a.txt"},
+				{`c:/DATA/Logs/Today.log`, "Logs/Today.log"},
+				{`Logs\Today.log`, "Logs/Today.log"},
+				{`logs\today.log`, "logs/today.log"},
+				{`D:\data\a.txt`, "err"},
+				{`\data\a.txt`, "a.txt"},
+				{`\other\a.txt`, "err"},
+				{`..\a.txt`, "err"},
+			},
+		},
+		"Windows FoldCase": {
+			&NativeFS{P: Windows, Root: `\\server\share`, FS: mapFS, FoldCase: true},
+			[]Test{
+				{`\\server\share\a.txt`, "a.txt"},
+				{`\\SERVER\Share\A.TXT`, "a.txt"},
+				{`logs\today.log`, "Logs/Today.log"},
+				{`LOGS\missing.log`, "LOGS/missing.log"},
+				{`\\server\other\a.txt`, "err"},
+			},
+		},
+	}
+
+	for implName, implTest := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			for _, test := range implTest.tests {
+				t.Run(test.path, func(t *testing.T) {
+					got, err := implTest.fsys.Name(test.path)
+					if test.want == "err" {
+						if err == nil {
+							t.Errorf("wrong result for Name(%q)\ngot:  %s\nwant: an error", test.path, got)
+						}
+						if !errors.Is(err, fs.ErrInvalid) {
+							t.Errorf("error for Name(%q) does not wrap fs.ErrInvalid: %v", test.path, err)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error for Name(%q): %s", test.path, err)
+					}
+					if got != test.want {
+						t.Errorf("wrong result for Name(%q)\ngot:  %s\nwant: %s", test.path, got, test.want)
+					}
+				})
+			}
+		})
+	}
+
+	t.Run("operations", func(t *testing.T) {
+		fsys := &NativeFS{P: Windows, Root: `C:\data`, FS: mapFS, FoldCase: true}
+
+		data, err := fsys.ReadFile(`C:\data\logs\TODAY.LOG`)
+		if err != nil {
+			t.Fatalf("unexpected error from ReadFile: %s", err)
+		}
+		if got, want := string(data), "today"; got != want {
+			t.Errorf("wrong file content\ngot:  %s\nwant: %s", got, want)
+		}
+
+		info, err := fsys.Stat(`C:\data\LOGS`)
+		if err != nil {
+			t.Fatalf("unexpected error from Stat: %s", err)
+		}
+		if !info.IsDir() {
+			t.Errorf("Stat result is not a directory")
+		}
+
+		entries, err := fsys.ReadDir(`C:\data`)
+		if err != nil {
+			t.Fatalf("unexpected error from ReadDir: %s", err)
+		}
+		if got, want := len(entries), 2; got != want {
+			t.Errorf("wrong number of entries %d; want %d", got, want)
+		}
+
+		f, err := fsys.Open(`a.txt`)
+		if err != nil {
+			t.Fatalf("unexpected error from Open: %s", err)
+		}
+		f.Close()
+
+		if _, err := fsys.Open(`C:\data\missing`); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("wrong error for missing file: %v", err)
+		}
+		if _, err := fsys.ReadFile(`C:\other`); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("wrong error for path outside root: %v", err)
+		}
+	})
+}
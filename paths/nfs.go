@@ -0,0 +1,196 @@
+package paths
+
+import (
+	"bufio"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// NFSPath is a path on an NFS server, in the "server:/export/path" form used
+// by mount and in /etc/fstab. The path is always a Unix path.
+type NFSPath struct {
+	// Host is the name or address of the server. An IPv6 address is
+	// written without brackets.
+	Host string
+
+	// Path is the absolute Unix path of the file on the server.
+	Path string
+}
+
+// ParseNFSPath parses a path in the "server:/export/path" form. An IPv6
+// address must be written in brackets, as in "[fe80::1]:/export".
+//
+// Returns a *RemotePathError if there is no server or if the path is not
+// absolute.
+func ParseNFSPath(s string) (NFSPath, error) {
+	fail := func(err error) (NFSPath, error) {
+		return NFSPath{}, &RemotePathError{Path: s, Err: err}
+	}
+	var host, path string
+	if strings.HasPrefix(s, "[") {
+		end := strings.Index(s, "]:")
+		if end < 0 {
+			return fail(ErrUnclosedHost)
+		}
+		host, path = s[1:end], s[end+2:]
+	} else {
+		var ok bool
+		host, path, ok = strings.Cut(s, ":")
+		if !ok {
+			return fail(ErrNotRemotePath)
+		}
+	}
+	if host == "" {
+		return fail(ErrEmptyHost)
+	}
+	if !Unix.IsAbs(path) {
+		return fail(ErrRemotePathNotAbs)
+	}
+	return NFSPath{Host: host, Path: Unix.Clean(path)}, nil
+}
+
+// String returns the path in the "server:/export/path" form.
+func (np NFSPath) String() string {
+	host := np.Host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return host + ":" + Unix.Clean(np.Path)
+}
+
+// URL returns the nfs:// URL for the path, like "nfs://server/export/path".
+func (np NFSPath) URL() *url.URL {
+	host := np.Host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return &url.URL{
+		Scheme: "nfs",
+		Host:   host,
+		Path:   Unix.Clean(np.Path),
+	}
+}
+
+// NFSPathFromURL returns the NFS path identified by the given nfs:// URL.
+// Any port, query or fragment in the URL is ignored, since the
+// "server:/export/path" form cannot represent them.
+//
+// Returns a *URLError with reason BadScheme if the URL does not use the nfs
+// scheme, MissingHost if it has no server, or UserInfo if it has a user
+// portion.
+func NFSPathFromURL(u *url.URL) (NFSPath, error) {
+	fail := func(reason URLErrorReason) (NFSPath, error) {
+		return NFSPath{}, &URLError{URL: u, Reason: reason, Scheme: "nfs"}
+	}
+	if !strings.EqualFold(u.Scheme, "nfs") {
+		return fail(BadScheme)
+	}
+	if u.User != nil {
+		return fail(UserInfo)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fail(MissingHost)
+	}
+	return NFSPath{Host: host, Path: Unix.Clean("/" + u.Path)}, nil
+}
+
+// NFSMountTable is a table of NFS filesystems mounted on a Unix system, each
+// with the server's export path as its Remote path.
+type NFSMountTable []Mount
+
+// ParseNFSMountTable parses the NFS filesystems from the given mount table
+// in the format of /proc/mounts or /etc/fstab, with one filesystem on each
+// line, and whitespace-separated fields giving the source, the mount point
+// and the filesystem type.
+//
+// Lines for filesystem types other than nfs and nfs4, blank lines, and
+// comments starting with "#" are ignored, along with any lines whose source
+// is not a valid NFS path. Octal escapes like "\040" in the source and
+// mount point are decoded.
+func ParseNFSMountTable(table string) NFSMountTable {
+	var ret NFSMountTable
+	sc := bufio.NewScanner(strings.NewReader(table))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[2] != "nfs" && fields[2] != "nfs4" {
+			continue
+		}
+		np, err := ParseNFSPath(unescapeMountField(fields[0]))
+		if err != nil {
+			continue
+		}
+		ret = append(ret, Mount{
+			Host:   np.Host,
+			Remote: np.Path,
+			Local:  Unix.Clean(unescapeMountField(fields[1])),
+		})
+	}
+	return ret
+}
+
+// LocalPath returns the local path of the given NFS path, using the mount for
+// the same server whose export path is the longest prefix of the path,
+// comparing whole components. The second return value is false if no mount
+// contains the path.
+//
+// For example, if server:/export is mounted at /mnt/export then the NFS path
+// server:/export/a/b has the local path /mnt/export/a/b.
+func (t NFSMountTable) LocalPath(np NFSPath) (string, bool) {
+	m, rest := longestMount(t, func(m *Mount) ([]string, bool) {
+		if !strings.EqualFold(m.Host, np.Host) {
+			return nil, false
+		}
+		return trimComponents(Unix, np.Path, m.Remote)
+	})
+	if m == nil {
+		return "", false
+	}
+	return Unix.Join(append([]string{m.Local}, rest...)...), true
+}
+
+// NFSPath is the inverse of LocalPath, returning the NFS path of the given
+// local path using the mount whose mount point is the longest prefix of the
+// path. The second return value is false if no mount contains the path.
+func (t NFSMountTable) NFSPath(path string) (NFSPath, bool) {
+	m, rest := longestMount(t, func(m *Mount) ([]string, bool) {
+		return trimComponents(Unix, path, m.Local)
+	})
+	if m == nil {
+		return NFSPath{}, false
+	}
+	return NFSPath{
+		Host: m.Host,
+		Path: Unix.Join(append([]string{m.Remote}, rest...)...),
+	}, true
+}
+
+// HostPolicy returns a HostPolicy for use with FromURLHost that maps file:
+// URLs for NFS servers through the table, as for LocalPath.
+func (t NFSMountTable) HostPolicy() HostPolicy {
+	return MountPolicy(Unix, t)
+}
+
+// unescapeMountField decodes the octal escapes, like "\040" for a space, that
+// the mount tables use for characters that would otherwise separate fields.
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				buf.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		buf.WriteByte(s[i])
+	}
+	return buf.String()
+}
@@ -0,0 +1,186 @@
+package paths
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestParseNFSPath(t *testing.T) {
+	tests := []struct {
+		s    string
+		want NFSPath
+		back string
+		err  error
+	}{
+		{"server:/export/a", NFSPath{"server", "/export/a"}, "", nil},
+		{"server:/", NFSPath{"server", "/"}, "", nil},
+		{"server:/export//a/../b/", NFSPath{"server", "/export/b"}, "server:/export/b", nil},
+		{"192.0.2.1:/export", NFSPath{"192.0.2.1", "/export"}, "", nil},
+		{"[fe80::1]:/export", NFSPath{"fe80::1", "/export"}, "", nil},
+		{"server", NFSPath{}, "", ErrNotRemotePath},
+		{":/export", NFSPath{}, "", ErrEmptyHost},
+		{"server:export", NFSPath{}, "", ErrRemotePathNotAbs},
+		{"[fe80::1]/export", NFSPath{}, "", ErrUnclosedHost},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			got, err := ParseNFSPath(test.s)
+			if test.err != nil {
+				var pathErr *RemotePathError
+				if !errors.As(err, &pathErr) || pathErr.Path != test.s {
+					t.Fatalf("wrong error for ParseNFSPath(%q)\ngot:  %v\nwant: a RemotePathError", test.s, err)
+				}
+				if !errors.Is(err, test.err) {
+					t.Errorf("wrong error for ParseNFSPath(%q)\ngot:  %v\nwant: %v", test.s, err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for ParseNFSPath(%q): %s", test.s, err)
+			}
+			if got != test.want {
+				t.Errorf("wrong result for ParseNFSPath(%q)\ngot:  %#v\nwant: %#v", test.s, got, test.want)
+			}
+			want := test.back
+			if want == "" {
+				want = test.s
+			}
+			if got.String() != want {
+				t.Errorf("wrong result from String\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestNFSURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want NFSPath
+		back string
+	}{
+		{"nfs://server/export/a", NFSPath{"server", "/export/a"}, ""},
+		{"NFS://server:2049/export/a%20b/", NFSPath{"server", "/export/a b"}, "nfs://server/export/a%20b"},
+		{"nfs://server", NFSPath{"server", "/"}, "nfs://server/"},
+		{"nfs://[::1]/export", NFSPath{"::1", "/export"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			u, err := url.Parse(test.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := NFSPathFromURL(u)
+			if err != nil {
+				t.Fatalf("unexpected error for NFSPathFromURL(%q): %s", test.url, err)
+			}
+			if got != test.want {
+				t.Errorf("wrong result for NFSPathFromURL(%q)\ngot:  %#v\nwant: %#v", test.url, got, test.want)
+			}
+			want := test.back
+			if want == "" {
+				want = test.url
+			}
+			if back := got.URL().String(); back != want {
+				t.Errorf("wrong result from URL\ngot:  %s\nwant: %s", back, want)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for s, reason := range map[string]URLErrorReason{
+			"file://server/export":     BadScheme,
+			"nfs:///export":            MissingHost,
+			"nfs://alice@server/share": UserInfo,
+		} {
+			u, err := url.Parse(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = NFSPathFromURL(u)
+			var urlErr *URLError
+			if !errors.As(err, &urlErr) || urlErr.Reason != reason {
+				t.Errorf("wrong error for NFSPathFromURL(%q): %v", s, err)
+			}
+		}
+		u, _ := url.Parse("nfs:///export")
+		_, err := NFSPathFromURL(u)
+		if got, want := err.Error(), "nfs: URLs must include a hostname"; got != want {
+			t.Errorf("wrong error message\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+}
+
+func TestNFSMountTable(t *testing.T) {
+	table := ParseNFSMountTable(`
+# device        mount point      type  options
+nfs1:/export    /mnt/export      nfs   rw 0 0
+nfs1:/export/home /home          nfs4  rw 0 0
+[fe80::1]:/data /mnt/my\040data  nfs   ro 0 0
+/dev/sda1       /                ext4  rw 0 0
+//fs/share      /mnt/share       cifs  rw 0 0
+`)
+	want := NFSMountTable{
+		{Host: "nfs1", Remote: "/export", Local: "/mnt/export"},
+		{Host: "nfs1", Remote: "/export/home", Local: "/home"},
+		{Host: "fe80::1", Remote: "/data", Local: "/mnt/my data"},
+	}
+	if len(table) != len(want) {
+		t.Fatalf("wrong result from ParseNFSMountTable\ngot:  %#v\nwant: %#v", table, want)
+	}
+	for i := range want {
+		if table[i] != want[i] {
+			t.Errorf("wrong mount %d from ParseNFSMountTable\ngot:  %#v\nwant: %#v", i, table[i], want[i])
+		}
+	}
+
+	tests := []struct {
+		nfs, local string
+	}{
+		{"nfs1:/export/a", "/mnt/export/a"},
+		{"NFS1:/export/home/alice", "/home/alice"},
+		{"nfs1:/export/homes", "/mnt/export/homes"},
+		{"nfs1:/export", "/mnt/export"},
+		{"[fe80::1]:/data/x", "/mnt/my data/x"},
+		{"nfs1:/other", ""},
+		{"nfs2:/export/a", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.nfs, func(t *testing.T) {
+			np, err := ParseNFSPath(test.nfs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := table.LocalPath(np)
+			if !ok {
+				got = ""
+			}
+			if got != test.local {
+				t.Errorf("wrong result for LocalPath(%q)\ngot:  %s\nwant: %s", test.nfs, got, test.local)
+			}
+			if !ok {
+				return
+			}
+			back, ok := table.NFSPath(got)
+			if !ok {
+				t.Fatalf("no result for NFSPath(%q)", got)
+			}
+			if back.Path != np.Path || !sameWord(Windows, back.Host, np.Host) {
+				t.Errorf("wrong result for NFSPath(%q)\ngot:  %s\nwant: %s", got, back, test.nfs)
+			}
+		})
+	}
+
+	if _, ok := table.NFSPath("/var/tmp"); ok {
+		t.Errorf("NFSPath succeeded for a path outside all mounts")
+	}
+
+	u, _ := url.Parse("file://nfs1/export/home/bob")
+	_, path, err := FromURLHost(Unix, u, table.HostPolicy())
+	if err != nil || path != "/home/bob" {
+		t.Errorf("wrong result for FromURLHost with HostPolicy\ngot:  %q, %v\nwant: %q", path, err, "/home/bob")
+	}
+}
@@ -0,0 +1,180 @@
+package paths
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ObjectKey is a P implementation for the keys of objects in an object store
+// such as Amazon S3 or Google Cloud Storage.
+//
+// Object stores have no directories, and so a key is just a string in which
+// "//", "." and ".." have no special meaning. Unlike Slash, ObjectKey never
+// rewrites a key: Clean returns its argument unchanged, and Join adds a
+// delimiter only where one is needed, so that "a/" and "b" produce "a/b".
+// Dir, Base and Split divide a key at its last delimiter, which is "/"
+// unless Delimiter is set.
+//
+// Functions in this package that compare keys by their parts, such as
+// HasPathPrefix and CommonAncestor, split a key at every delimiter without
+// cleaning it, and so "a//b" has an empty part and ".." is an ordinary part.
+//
+// No key is absolute and no key has a volume name. ToURL and FromURL
+// convert between keys and URLs like "s3://bucket/key".
+type ObjectKey struct {
+	// Bucket is the name of the bucket for ToURL, and the only bucket that
+	// FromURL accepts unless it is empty.
+	Bucket string
+
+	// Scheme is the URL scheme for ToURL and FromURL, or empty for "s3".
+	// Use "gs" for Google Cloud Storage.
+	Scheme string
+
+	// Delimiter is the string that separates the parts of a key, or empty
+	// for "/".
+	Delimiter string
+}
+
+var _ P = ObjectKey{}
+
+func (k ObjectKey) Base(path string) string {
+	_, file := k.Split(path)
+	return file
+}
+
+func (k ObjectKey) Clean(path string) string {
+	return path
+}
+
+// Dir returns all but the last part of the key, without the delimiter that
+// precedes the last part, or an empty string if the key has no delimiter.
+func (k ObjectKey) Dir(path string) string {
+	dir, _ := k.Split(path)
+	return strings.TrimSuffix(dir, k.delimiter())
+}
+
+func (k ObjectKey) Ext(path string) string {
+	base := k.Base(path)
+	if i := strings.LastIndexByte(base, '.'); i >= 0 {
+		return base[i:]
+	}
+	return ""
+}
+
+func (k ObjectKey) IsAbs(path string) bool {
+	return false
+}
+
+// Join concatenates the non-empty elements with the delimiter between them,
+// except where an element already ends with the delimiter or the next one
+// already starts with it.
+func (k ObjectKey) Join(elems ...string) string {
+	delim := k.delimiter()
+	var buf strings.Builder
+	for _, elem := range elems {
+		if elem == "" {
+			continue
+		}
+		if buf.Len() > 0 && !strings.HasSuffix(buf.String(), delim) && !strings.HasPrefix(elem, delim) {
+			buf.WriteString(delim)
+		}
+		buf.WriteString(elem)
+	}
+	return buf.String()
+}
+
+// Rel returns the part of the target key after the base key and a
+// delimiter, or an empty string if the keys are equal. The target must
+// start with the base key exactly, because there is no way to step out of
+// a prefix.
+func (k ObjectKey) Rel(basepath, targpath string) (string, error) {
+	if basepath == "" || basepath == targpath {
+		return strings.TrimPrefix(targpath, basepath), nil
+	}
+	prefix := basepath
+	if !strings.HasSuffix(prefix, k.delimiter()) {
+		prefix += k.delimiter()
+	}
+	if !strings.HasPrefix(targpath, prefix) {
+		return "", &RelError{Base: basepath, Target: targpath}
+	}
+	return targpath[len(prefix):], nil
+}
+
+// Split splits the key immediately after its last delimiter, so that the
+// directory part keeps the delimiter, as for a prefix in a listing request.
+func (k ObjectKey) Split(path string) (dir, file string) {
+	i := strings.LastIndex(path, k.delimiter())
+	if i < 0 {
+		return "", path
+	}
+	i += len(k.delimiter())
+	return path[:i], path[i:]
+}
+
+func (k ObjectKey) VolumeName(path string) string {
+	return ""
+}
+
+// ToURL returns a URL like "s3://bucket/key" for the key in Bucket.
+func (k ObjectKey) ToURL(path string) *url.URL {
+	return &url.URL{
+		Scheme: k.scheme(),
+		Host:   k.Bucket,
+		Path:   "/" + path,
+	}
+}
+
+// FromURL returns the key from a URL like "s3://bucket/key", which is
+// everything in the path after the slash that follows the bucket name.
+// Object store tools treat the rest of such a URL as part of the key, and so
+// a query or fragment is also part of the key, as in "s3://bucket/a?b#c" for
+// the key "a?b#c".
+//
+// Returns a *URLError with reason BadScheme if the URL does not use the
+// expected scheme, UserInfo if it has a user portion, MissingHost if it
+// has no bucket name, or NonLocalHost if Bucket is set and the URL refers
+// to a different bucket.
+func (k ObjectKey) FromURL(u *url.URL) (string, error) {
+	fail := func(reason URLErrorReason) (string, error) {
+		return "", &URLError{URL: u, Reason: reason, Scheme: k.scheme()}
+	}
+	if !strings.EqualFold(u.Scheme, k.scheme()) {
+		return fail(BadScheme)
+	}
+	if u.User != nil {
+		return fail(UserInfo)
+	}
+	if u.Host == "" {
+		return fail(MissingHost)
+	}
+	if k.Bucket != "" && u.Host != k.Bucket {
+		return fail(NonLocalHost)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.ForceQuery || u.RawQuery != "" {
+		query, err := url.PathUnescape(u.RawQuery)
+		if err != nil {
+			query = u.RawQuery
+		}
+		key += "?" + query
+	}
+	if u.Fragment != "" {
+		key += "#" + u.Fragment
+	}
+	return key, nil
+}
+
+func (k ObjectKey) delimiter() string {
+	if k.Delimiter == "" {
+		return "/"
+	}
+	return k.Delimiter
+}
+
+func (k ObjectKey) scheme() string {
+	if k.Scheme == "" {
+		return "s3"
+	}
+	return k.Scheme
+}
@@ -0,0 +1,180 @@
+package paths
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestObjectKey(t *testing.T) {
+	k := ObjectKey{Bucket: "artifacts"}
+
+	for _, key := range []string{"a//b", "a/../b", "./a", "a/", "/a", ""} {
+		if got := k.Clean(key); got != key {
+			t.Errorf("wrong result for Clean(%q)\ngot:  %s\nwant: %s", key, got, key)
+		}
+	}
+
+	splitTests := []struct {
+		key, dir, base, ext string
+	}{
+		{"a/b/c.tar.gz", "a/b", "c.tar.gz", ".gz"},
+		{"a//b", "a/", "b", ""},
+		{"a/b/", "a/b", "", ""},
+		{"a/../b", "a/..", "b", ""},
+		{"/a", "", "a", ""},
+		{"a.txt", "", "a.txt", ".txt"},
+		{"", "", "", ""},
+	}
+	for _, test := range splitTests {
+		if got := k.Dir(test.key); got != test.dir {
+			t.Errorf("wrong result for Dir(%q)\ngot:  %s\nwant: %s", test.key, got, test.dir)
+		}
+		if got := k.Base(test.key); got != test.base {
+			t.Errorf("wrong result for Base(%q)\ngot:  %s\nwant: %s", test.key, got, test.base)
+		}
+		if got := k.Ext(test.key); got != test.ext {
+			t.Errorf("wrong result for Ext(%q)\ngot:  %s\nwant: %s", test.key, got, test.ext)
+		}
+		if dir, file := k.Split(test.key); dir+file != test.key {
+			t.Errorf("Split(%q) returned %q and %q, which do not concatenate to the key", test.key, dir, file)
+		}
+	}
+
+	joinTests := []struct {
+		elems []string
+		want  string
+	}{
+		{[]string{"a", "b", "c"}, "a/b/c"},
+		{[]string{"a/", "b"}, "a/b"},
+		{[]string{"a", "/b"}, "a/b"},
+		{[]string{"a/", "/b"}, "a//b"},
+		{[]string{"a", "", "..", "b"}, "a/../b"},
+		{[]string{"", "a"}, "a"},
+		{nil, ""},
+	}
+	for _, test := range joinTests {
+		if got := k.Join(test.elems...); got != test.want {
+			t.Errorf("wrong result for Join(%q)\ngot:  %s\nwant: %s", test.elems, got, test.want)
+		}
+	}
+
+	relTests := []struct {
+		base, targ, want string
+	}{
+		{"a/b", "a/b/c/d", "c/d"},
+		{"a/b/", "a/b/c", "c"},
+		{"a/b", "a/b", ""},
+		{"", "a/b", "a/b"},
+		{"a/b", "a/bc", "err"},
+		{"a/b", "a/c", "err"},
+	}
+	for _, test := range relTests {
+		got, err := k.Rel(test.base, test.targ)
+		if test.want == "err" {
+			if !errors.Is(err, ErrNotRelative) {
+				t.Errorf("wrong result for Rel(%q, %q)\ngot:  %q, %v\nwant: error", test.base, test.targ, got, err)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("wrong result for Rel(%q, %q)\ngot:  %q, %v\nwant: %q", test.base, test.targ, got, err, test.want)
+		}
+		if back := k.Join(test.base, got); back != test.targ {
+			t.Errorf("Join(%q, %q) does not reverse Rel\ngot:  %s\nwant: %s", test.base, got, back, test.targ)
+		}
+	}
+
+	if k.IsAbs("/a") || k.VolumeName("c:/a") != "" {
+		t.Errorf("keys must not be absolute or have volume names")
+	}
+}
+
+func TestObjectKeyDelimiter(t *testing.T) {
+	k := ObjectKey{Delimiter: "::"}
+	if got, want := k.Join("a", "b::", "c"), "a::b::c"; got != want {
+		t.Errorf("wrong result for Join\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := k.Dir("a/b::c::d"), "a/b::c"; got != want {
+		t.Errorf("wrong result for Dir\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := k.Base("a/b::c::d"), "d"; got != want {
+		t.Errorf("wrong result for Base\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestObjectKeyURL(t *testing.T) {
+	tests := []struct {
+		k   ObjectKey
+		key string
+		url string
+	}{
+		{ObjectKey{Bucket: "artifacts"}, "a/b.zip", "s3://artifacts/a/b.zip"},
+		{ObjectKey{Bucket: "artifacts"}, "a//../b c", "s3://artifacts/a//../b%20c"},
+		{ObjectKey{Bucket: "artifacts"}, "/a", "s3://artifacts//a"},
+		{ObjectKey{Bucket: "artifacts"}, "", "s3://artifacts/"},
+		{ObjectKey{Bucket: "data", Scheme: "gs"}, "x/y", "gs://data/x/y"},
+		{ObjectKey{Bucket: "artifacts"}, "a?b#c", "s3://artifacts/a%3Fb%23c"},
+	}
+	for _, test := range tests {
+		u := test.k.ToURL(test.key)
+		if got := u.String(); got != test.url {
+			t.Errorf("wrong result for ToURL(%q)\ngot:  %s\nwant: %s", test.key, got, test.url)
+		}
+		parsed, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := test.k.FromURL(parsed)
+		if err != nil {
+			t.Errorf("unexpected error for FromURL(%q): %s", test.url, err)
+		} else if got != test.key {
+			t.Errorf("wrong result for FromURL(%q)\ngot:  %s\nwant: %s", test.url, got, test.key)
+		}
+	}
+
+	// Object store tools don't treat "?" and "#" as special in a key
+	literalTests := map[string]string{
+		"s3://artifacts/a?b#c":    "a?b#c",
+		"s3://artifacts/a?":       "a?",
+		"s3://artifacts/a?b%20c":  "a?b c",
+		"s3://artifacts/a#b/c":    "a#b/c",
+		"s3://artifacts/a%3Fb?/c": "a?b?/c",
+	}
+	for s, want := range literalTests {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := (ObjectKey{}).FromURL(u)
+		if err != nil {
+			t.Errorf("unexpected error for FromURL(%q): %s", s, err)
+		} else if got != want {
+			t.Errorf("wrong result for FromURL(%q)\ngot:  %s\nwant: %s", s, got, want)
+		}
+	}
+
+	errorTests := []struct {
+		k      ObjectKey
+		url    string
+		reason URLErrorReason
+	}{
+		{ObjectKey{}, "gs://artifacts/a", BadScheme},
+		{ObjectKey{}, "s3:///a", MissingHost},
+		{ObjectKey{}, "s3://user@artifacts/a", UserInfo},
+		{ObjectKey{Bucket: "artifacts"}, "s3://other/a", NonLocalHost},
+	}
+	for _, test := range errorTests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = test.k.FromURL(u)
+		if !errors.Is(err, test.reason) {
+			t.Errorf("wrong error for FromURL(%q): %v", test.url, err)
+		}
+	}
+	if got, err := (ObjectKey{}).FromURL(&url.URL{Scheme: "s3", Host: "any", Path: "/k"}); err != nil || got != "k" {
+		t.Errorf("FromURL without a bucket should accept any bucket\ngot:  %q, %v", got, err)
+	}
+}
@@ -0,0 +1,203 @@
+package paths
+
+import (
+	"iter"
+	"slices"
+	"strings"
+)
+
+// PathMap is a map from paths to values of type V, where two paths are
+// considered to be the same key if they are equal after cleaning, using the
+// case rules of a particular path implementation. For example, a PathMap for
+// the Windows implementation treats `C:\A` and `c:/a` as the same key.
+//
+// A PathMap remembers the spelling of each path as it was first inserted,
+// and reports paths using that spelling. Use NewPathMap to create a PathMap.
+// A PathMap is not safe for concurrent use.
+type PathMap[V any] struct {
+	p       P
+	entries map[string]pathMapEntry[V]
+}
+
+type pathMapEntry[V any] struct {
+	path  string
+	value V
+}
+
+// NewPathMap returns a new, empty PathMap that uses the given path
+// implementation.
+func NewPathMap[V any](p P) *PathMap[V] {
+	return &PathMap[V]{
+		p:       p,
+		entries: make(map[string]pathMapEntry[V]),
+	}
+}
+
+// Set associates the given value with the given path, replacing any value
+// already associated with an equivalent path. If there is already such a
+// path then the map retains its original spelling.
+func (m *PathMap[V]) Set(path string, v V) {
+	key := pathKey(m.p, path)
+	if existing, ok := m.entries[key]; ok {
+		path = existing.path
+	}
+	m.entries[key] = pathMapEntry[V]{path: path, value: v}
+}
+
+// Get returns the value associated with the given path, or with an
+// equivalent path. The second return value is false if there is no
+// such path.
+func (m *PathMap[V]) Get(path string) (V, bool) {
+	entry, ok := m.entries[pathKey(m.p, path)]
+	return entry.value, ok
+}
+
+// Path returns the spelling of the path in the map that is equivalent to
+// the given path, as it was first inserted. The second return value is false
+// if there is no such path.
+func (m *PathMap[V]) Path(path string) (string, bool) {
+	entry, ok := m.entries[pathKey(m.p, path)]
+	return entry.path, ok
+}
+
+// Delete removes the given path, or an equivalent path, from the map.
+func (m *PathMap[V]) Delete(path string) {
+	delete(m.entries, pathKey(m.p, path))
+}
+
+// Len returns the number of paths in the map.
+func (m *PathMap[V]) Len() int {
+	return len(m.entries)
+}
+
+// All returns an iterator over the paths in the map and their values, in
+// the order described for ComparePaths.
+//
+// The iterator ranges over a snapshot of the map taken when iteration begins,
+// and so the map may be modified during iteration.
+func (m *PathMap[V]) All() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		entries := make([]pathMapEntry[V], 0, len(m.entries))
+		for _, entry := range m.entries {
+			entries = append(entries, entry)
+		}
+		slices.SortFunc(entries, func(a, b pathMapEntry[V]) int {
+			return ComparePaths(m.p, a.path, b.path)
+		})
+		for _, entry := range entries {
+			if !yield(entry.path, entry.value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the paths in the map, in the same order
+// as All.
+func (m *PathMap[V]) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for path := range m.All() {
+			if !yield(path) {
+				return
+			}
+		}
+	}
+}
+
+// PathSet is a set of paths, where two paths are considered to be the same
+// member if they are equal after cleaning, using the case rules of
+// a particular path implementation, as for PathMap.
+//
+// Use NewPathSet to create a PathSet. A PathSet is not safe for concurrent
+// use.
+type PathSet struct {
+	m *PathMap[struct{}]
+}
+
+// NewPathSet returns a new PathSet that uses the given path implementation,
+// containing the given paths.
+func NewPathSet(p P, paths ...string) *PathSet {
+	s := &PathSet{m: NewPathMap[struct{}](p)}
+	for _, path := range paths {
+		s.Add(path)
+	}
+	return s
+}
+
+// Add adds the given path to the set, unless the set already contains an
+// equivalent path.
+func (s *PathSet) Add(path string) {
+	s.m.Set(path, struct{}{})
+}
+
+// Has returns true if the set contains the given path or an equivalent path.
+func (s *PathSet) Has(path string) bool {
+	_, ok := s.m.Get(path)
+	return ok
+}
+
+// Path returns the spelling of the path in the set that is equivalent to
+// the given path, as it was first added. The second return value is false
+// if there is no such path.
+func (s *PathSet) Path(path string) (string, bool) {
+	return s.m.Path(path)
+}
+
+// Remove removes the given path, or an equivalent path, from the set.
+func (s *PathSet) Remove(path string) {
+	s.m.Delete(path)
+}
+
+// Len returns the number of paths in the set.
+func (s *PathSet) Len() int {
+	return s.m.Len()
+}
+
+// All returns an iterator over the paths in the set, in the order described
+// for ComparePaths.
+func (s *PathSet) All() iter.Seq[string] {
+	return s.m.Keys()
+}
+
+// ComparePaths compares two paths component by component, using the case
+// rules of the given path implementation, returning a negative number if a
+// sorts before b, a positive number if a sorts after b, or zero if they are
+// equivalent.
+//
+// Paths are ordered first by volume name and then with relative paths
+// before rooted paths. Components are compared in turn, so that every path
+// sorts immediately before the paths within it: "a" and then "a/b" sort
+// before "a-b", unlike in a byte-wise comparison.
+func ComparePaths(p P, a, b string) int {
+	aVol, aRooted, aElems := splitComponents(p, a)
+	bVol, bRooted, bElems := splitComponents(p, b)
+	if c := compareWords(p, aVol, bVol); c != 0 {
+		return c
+	}
+	switch {
+	case aRooted && !bRooted:
+		return 1
+	case !aRooted && bRooted:
+		return -1
+	}
+	for i := 0; i < len(aElems) && i < len(bElems); i++ {
+		if c := compareWords(p, aElems[i], bElems[i]); c != 0 {
+			return c
+		}
+	}
+	return len(aElems) - len(bElems)
+}
+
+// compareWords compares two path components by their wordKey, so that
+// components that are equivalent under the case rules of the given path
+// implementation are equal.
+func compareWords(p P, a, b string) int {
+	return strings.Compare(wordKey(p, a), wordKey(p, b))
+}
+
+// pathKey returns the map key for the given path, which is equal for any
+// two paths that are the same after cleaning when compared using the case
+// rules of the given path implementation.
+func pathKey(p P, path string) string {
+	return wordKey(p, p.Clean(path))
+}
@@ -0,0 +1,110 @@
+package paths
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestPathMap(t *testing.T) {
+	type Test struct {
+		set  []string
+		get  string
+		want string
+	}
+
+	implTests := map[string][]Test{
+		"Unix": {
+			{[]string{"/a/b"}, "/a/b", "/a/b"},
+			{[]string{"/a/b"}, "/a//b/./", "/a/b"},
+			{[]string{"/a/b"}, "/a/c/../b", "/a/b"},
+			{[]string{"/a/b"}, "/A/B", ""},
+			{[]string{"a", "./a"}, "a", "a"},
+			{[]string{"/a"}, "a", ""},
+		},
+		"Darwin": {
+			{[]string{"/Users/Alice"}, "/users/alice", "/Users/Alice"},
+			{[]string{"/caf\u00e9", "/CAFE\u0301"}, "/caf\u00e9", "/caf\u00e9"},
+		},
+		"Windows": {
+			{[]string{`C:\A`}, `c:/a`, `C:\A`},
+			{[]string{`c:/a`, `C:\A`}, `C:\a\`, `c:/a`},
+			{[]string{`\\server\share\x`}, `//SERVER/Share/X`, `\\server\share\x`},
+			{[]string{`C:\a`}, `D:\a`, ""},
+			{[]string{`C:\a`}, `C:a`, ""},
+			{[]string{`C:\a`}, `\a`, ""},
+		},
+	}
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Darwin":  Darwin,
+		"Windows": Windows,
+	}
+
+	for implName, tests := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			for _, test := range tests {
+				t.Run(test.get, func(t *testing.T) {
+					m := NewPathMap[int](p)
+					for i, path := range test.set {
+						m.Set(path, i)
+					}
+					got, ok := m.Path(test.get)
+					if !ok {
+						got = ""
+					}
+					if got != test.want {
+						t.Errorf("wrong result for Path(%q)\ngot:  %s\nwant: %s", test.get, got, test.want)
+					}
+					if v, _ := m.Get(test.get); ok && v != len(test.set)-1 {
+						t.Errorf("wrong value for Get(%q): %d; want %d", test.get, v, len(test.set)-1)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestPathSetAll(t *testing.T) {
+	tests := map[string]struct {
+		p     P
+		paths []string
+		want  []string
+	}{
+		"Unix": {
+			Unix,
+			[]string{"a-b", "a/b", "a", "B", "/z", "/a", "a/b/", "b"},
+			[]string{"B", "a", "a/b", "a-b", "b", "/a", "/z"},
+		},
+		"Windows": {
+			Windows,
+			[]string{`D:\x`, `c:\b`, `C:\A`, `b`, `a`, `C:a`, `\\srv\share\x`, `C:\a\b`, `c:\a-b`},
+			[]string{`a`, `b`, `C:a`, `C:\A`, `C:\a\b`, `c:\a-b`, `c:\b`, `D:\x`, `\\srv\share\x`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewPathSet(test.p, test.paths...)
+			got := slices.Collect(s.All())
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+			}
+			if s.Len() != len(test.want) {
+				t.Errorf("wrong length %d; want %d", s.Len(), len(test.want))
+			}
+			for _, path := range test.paths {
+				if !s.Has(path) {
+					t.Errorf("set does not have %q", path)
+				}
+			}
+			for _, path := range test.paths {
+				s.Remove(path)
+			}
+			if s.Len() != 0 {
+				t.Errorf("set still has %d paths after removing all", s.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package paths
+
+import (
+	"errors"
+	"strings"
+)
+
+// powerShellProviderPrefix is the fully-qualified name of the PowerShell
+// FileSystem provider, as PowerShell itself writes it in PSPath properties.
+const powerShellProviderPrefix = `Microsoft.PowerShell.Core\FileSystem::`
+
+// PowerShellQuote returns the given Windows path as a single-quoted PowerShell
+// string literal, suitable for use as the argument to a -LiteralPath parameter.
+//
+// Single-quoted strings in PowerShell are not subject to variable expansion
+// or backtick escapes, so the only characters needing special treatment are
+// the single quotes themselves. PowerShell also accepts the typographic quote
+// characters U+2018 through U+201B as single quotes, so those are doubled too.
+func PowerShellQuote(path string) string {
+	var buf strings.Builder
+	buf.Grow(len(path) + 2)
+	buf.WriteByte('\'')
+	for _, r := range path {
+		if isPowerShellSingleQuote(r) {
+			buf.WriteRune(r)
+		}
+		buf.WriteRune(r)
+	}
+	buf.WriteByte('\'')
+	return buf.String()
+}
+
+// PowerShellEscapeWildcards returns the given Windows path with the PowerShell
+// wildcard characters escaped by backticks, in the same way as
+// WildcardPattern.Escape in PowerShell itself.
+//
+// Parameters named -Path in PowerShell cmdlets treat their argument as a
+// wildcard pattern, so a path containing square brackets must be escaped
+// to refer to only that exact file. The result still needs quoting before it
+// can be included in a PowerShell command, so most callers will want to pass
+// it to PowerShellQuote:
+//
+//	paths.PowerShellQuote(paths.PowerShellEscapeWildcards(path))
+func PowerShellEscapeWildcards(path string) string {
+	if !strings.ContainsAny(path, "*?[]`") {
+		return path
+	}
+	var buf strings.Builder
+	buf.Grow(len(path) + 4)
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '*', '?', '[', ']', '`':
+			buf.WriteByte('`')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
+
+// PowerShellProviderPath returns the given Windows path qualified with the
+// PowerShell FileSystem provider name, in the form PowerShell uses for
+// the PSPath property of filesystem items:
+//
+//	Microsoft.PowerShell.Core\FileSystem::C:\Windows
+//
+// Provider-qualified paths are not subject to interpretation relative to the
+// current PowerShell location, and so can be used even when the PowerShell
+// session's current location is in some other provider, such as the registry.
+func PowerShellProviderPath(path string) string {
+	return powerShellProviderPrefix + Windows.Clean(path)
+}
+
+// FromPowerShellProviderPath is the inverse of PowerShellProviderPath,
+// returning the plain Windows path for a provider-qualified path.
+//
+// The provider name may be written either as just "FileSystem" or qualified
+// with its module name as "Microsoft.PowerShell.Core\FileSystem", in any
+// letter case. A path that has no provider qualifier at all is returned
+// unchanged except for cleaning, including a path to an alternate data
+// stream like `C:\file.txt::$DATA`, whose "::" does not follow a provider
+// name. Returns an error if the path is qualified
+// with a provider other than FileSystem, such as "Registry::HKLM\Software".
+func FromPowerShellProviderPath(path string) (string, error) {
+	i := strings.Index(path, "::")
+	if i < 0 || !isPowerShellProviderName(path[:i]) {
+		return Windows.Clean(path), nil
+	}
+	provider, rest := path[:i], path[i+2:]
+	if sep := strings.LastIndexByte(provider, '\\'); sep >= 0 {
+		module := provider[:sep]
+		provider = provider[sep+1:]
+		if !strings.EqualFold(module, "Microsoft.PowerShell.Core") {
+			return "", errors.New("only paths from the Microsoft.PowerShell.Core module's FileSystem provider are allowed")
+		}
+	}
+	if !strings.EqualFold(provider, "FileSystem") {
+		return "", errors.New("only paths from the PowerShell FileSystem provider are allowed")
+	}
+	if rest == "" {
+		return "", errors.New("provider-qualified path has no path after the provider name")
+	}
+	return Windows.Clean(rest), nil
+}
+
+// isPowerShellProviderName returns true if the given string has the form of
+// a PowerShell provider name, optionally qualified with a module name as in
+// `Microsoft.PowerShell.Core\FileSystem`. Provider names consist only of
+// letters, digits and underscores, and module names may also contain dots
+// and hyphens, so a string containing a path separator other than the one
+// after the module name, or a drive letter colon, is not a provider name.
+func isPowerShellProviderName(s string) bool {
+	module, provider, qualified := strings.Cut(s, `\`)
+	if !qualified {
+		module, provider = "", s
+	} else if module == "" {
+		return false
+	}
+	if provider == "" {
+		return false
+	}
+	for _, c := range []byte(module) {
+		if !isPowerShellNameChar(c) && c != '.' && c != '-' {
+			return false
+		}
+	}
+	for _, c := range []byte(provider) {
+		if !isPowerShellNameChar(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isPowerShellNameChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_'
+}
+
+// isPowerShellSingleQuote returns true if the given character terminates
+// a single-quoted PowerShell string. In addition to the ASCII apostrophe,
+// PowerShell accepts the various typographic single quotes.
+func isPowerShellSingleQuote(r rune) bool {
+	switch r {
+	case '\'', '\u2018', '\u2019', '\u201A', '\u201B':
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,113 @@
+package paths
+
+import (
+	"testing"
+)
+
+func TestPowerShellQuote(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{``, `''`},
+		{`C:\Windows`, `'C:\Windows'`},
+		{`C:\Program Files\App`, `'C:\Program Files\App'`},
+		{`C:\Users\o'brien`, `'C:\Users\o''brien'`},
+		{`C:\$env:TEMP\x`, `'C:\$env:TEMP\x'`},
+		{"C:\\a`b", "'C:\\a`b'"},
+		{"C:\\it\u2019s", "'C:\\it\u2019\u2019s'"},
+		{"C:\\\u2018q\u201B", "'C:\\\u2018\u2018q\u201B\u201B'"},
+		{`\\server\share\[x]`, `'\\server\share\[x]'`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got := PowerShellQuote(test.path)
+			if got != test.want {
+				t.Errorf("wrong result for PowerShellQuote(%q)\ngot:  %s\nwant: %s", test.path, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPowerShellEscapeWildcards(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{``, ``},
+		{`C:\Windows`, `C:\Windows`},
+		{`C:\logs\[2019]\a.txt`, "C:\\logs\\`[2019`]\\a.txt"},
+		{"C:\\a`b", "C:\\a``b"},
+		{`C:\*.txt`, "C:\\`*.txt"},
+		{`C:\a?`, "C:\\a`?"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got := PowerShellEscapeWildcards(test.path)
+			if got != test.want {
+				t.Errorf("wrong result for PowerShellEscapeWildcards(%q)\ngot:  %s\nwant: %s", test.path, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPowerShellProviderPath(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{`C:\Windows`, `Microsoft.PowerShell.Core\FileSystem::C:\Windows`},
+		{`c:/windows/system32/`, `Microsoft.PowerShell.Core\FileSystem::c:\windows\system32`},
+		{`\\server\share`, `Microsoft.PowerShell.Core\FileSystem::\\server\share`},
+		{`\\server\share\a\..\b`, `Microsoft.PowerShell.Core\FileSystem::\\server\share\b`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got := PowerShellProviderPath(test.path)
+			if got != test.want {
+				t.Errorf("wrong result for PowerShellProviderPath(%q)\ngot:  %s\nwant: %s", test.path, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFromPowerShellProviderPath(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{`Microsoft.PowerShell.Core\FileSystem::C:\x`, `C:\x`},
+		{`microsoft.powershell.core\filesystem::C:\x`, `C:\x`},
+		{`FileSystem::\\server\share`, `\\server\share`},
+		{`FileSystem::\\server\share\dir\`, `\\server\share\dir`},
+		{`filesystem::C:/a/b`, `C:\a\b`},
+		{`C:\plain\path`, `C:\plain\path`},
+		{`relative\path`, `relative\path`},
+		{`C:\file.txt::$DATA`, `C:\file.txt::$DATA`},
+		{`\\server\share\file.txt::$DATA`, `\\server\share\file.txt::$DATA`},
+		{`file.txt::$DATA`, `file.txt::$DATA`},
+		{`FileSystem::C:\file.txt::$DATA`, `C:\file.txt::$DATA`},
+
+		{`Registry::HKEY_LOCAL_MACHINE\Software`, `err`},
+		{`Microsoft.PowerShell.Core\Registry::HKLM\Software`, `err`},
+		{`Other.Module\FileSystem::C:\x`, `err`},
+		{`FileSystem::`, `err`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got, err := FromPowerShellProviderPath(test.path)
+			if test.want == "err" {
+				if err == nil {
+					t.Errorf("wrong result for FromPowerShellProviderPath(%q)\ngot:  %s\nwant: an error", test.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for FromPowerShellProviderPath(%q): %s", test.path, err)
+			}
+			if got != test.want {
+				t.Errorf("wrong result for FromPowerShellProviderPath(%q)\ngot:  %s\nwant: %s", test.path, got, test.want)
+			}
+		})
+	}
+}
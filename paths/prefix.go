@@ -0,0 +1,46 @@
+package paths
+
+// HasPathPrefix returns true if the given prefix is the same path as the given
+// path or one of its ancestors, comparing whole components after cleaning
+// both paths.
+//
+// Unlike strings.HasPrefix, "/foo/bar" is not a prefix of "/foo/barbaz".
+// Components are compared using the case rules of the given path
+// implementation, so for Windows `C:\Users` is a prefix of `c:/users/alice`.
+// Volume names must also match, and so for Windows `C:\a` is not a prefix of
+// `D:\a\b` and `\\server\share` is not a prefix of `\\server\other`.
+// A rooted path is never a prefix of a relative path, or vice-versa, but the
+// current directory "." is a prefix of every relative path without a volume
+// name that does not begin with "..".
+//
+// For ObjectKey, neither path is cleaned and components are separated by
+// every delimiter, so "a//b" has an empty component and ".." is an ordinary
+// component. A key with a trailing delimiter, like "a/", is therefore a
+// prefix of "a/" and "a//b" but not of "a/b".
+func HasPathPrefix(p P, path, prefix string) bool {
+	_, ok := trimComponents(p, path, prefix)
+	return ok
+}
+
+// IsWithin returns true if the given path is a descendent of the given
+// directory, using the same rules as HasPathPrefix. Unlike HasPathPrefix,
+// a directory is not within itself.
+func IsWithin(p P, path, dir string) bool {
+	rest, ok := trimComponents(p, path, dir)
+	return ok && len(rest) != 0
+}
+
+// TrimPathPrefix removes the given prefix from the given path, using the same
+// rules as HasPathPrefix, and returns the remaining relative path. If the
+// prefix is the same path as the given path then the result is ".", or the
+// empty key for ObjectKey.
+//
+// The second return value is false if prefix is not a prefix of path, in
+// which case the path is returned unchanged.
+func TrimPathPrefix(p P, path, prefix string) (string, bool) {
+	rest, ok := trimComponents(p, path, prefix)
+	if !ok {
+		return path, false
+	}
+	return joinComponents(p, rest), true
+}
@@ -0,0 +1,110 @@
+package paths
+
+import (
+	"testing"
+)
+
+func TestPathPrefix(t *testing.T) {
+	type Test struct {
+		path, prefix string
+		want         string
+	}
+
+	// want is the result of TrimPathPrefix, or "no" if prefix is not a prefix
+	// of path. For ObjectKey, the result for the same key is empty.
+	implTests := map[string][]Test{
+		"Unix": {
+			{"/foo/bar/baz", "/foo/bar", "baz"},
+			{"/foo/barbaz", "/foo/bar", "no"},
+			{"/foo/bar", "/foo/bar", "."},
+			{"/foo/bar/", "/foo//bar/.", "."},
+			{"/foo/bar/baz", "/", "foo/bar/baz"},
+			{"/foo/bar/baz", "/foo/qux/../bar", "baz"},
+			{"/Foo/bar", "/foo", "no"},
+			{"/foo", "/foo/bar", "no"},
+			{"foo/bar", "foo", "bar"},
+			{"foo/bar", ".", "foo/bar"},
+			{"foo/bar", "/foo", "no"},
+			{"/foo/bar", "foo", "no"},
+			{"../foo", "..", "foo"},
+			{"../foo", ".", "no"},
+			{"../foo", "../bar", "no"},
+		},
+		"Windows": {
+			{`C:\Users\alice\proj`, `C:\Users`, `alice\proj`},
+			{`c:/users/alice`, `C:\Users\`, `alice`},
+			{`C:\Users\alice`, `C:\Users\al`, "no"},
+			{`D:\Users\alice`, `C:\Users`, "no"},
+			{`C:\a`, `C:\`, `a`},
+			{`C:a`, `C:\`, "no"},
+			{`C:a\b`, `C:a`, `b`},
+			{`\a\b`, `C:\a`, "no"},
+			{`\a\b`, `\a`, `b`},
+			{`\\server\share\dir\x`, `\\SERVER\Share`, `dir\x`},
+			{`\\server\share\dir`, `\\server\share\dir`, "."},
+			{`\\server\share2\dir`, `\\server\share`, "no"},
+			{`\\server\other\dir`, `\\server\share`, "no"},
+		},
+		"Slash": {
+			{"/foo/bar/baz", "/foo/bar", "baz"},
+			{"/foo/barbaz", "/foo/bar", "no"},
+			{`/foo\bar`, "/foo", "no"},
+			{`C:/foo/bar`, "C:", "foo/bar"},
+			{`C:/foo/bar`, "c:", "no"},
+		},
+		"ObjectKey": {
+			{"a/b/c", "a/b", "c"},
+			{"a/bc", "a/b", "no"},
+			{"a/b", "a/b", ""},
+			{"a/b", "", "a/b"},
+			{"a//b", "a/b", "no"},
+			{"a//b", "a/", "b"},
+			{"a/b", "a/", "no"},
+			{"a/../b", "a", "../b"},
+			{"a/../b", "b", "no"},
+			{"./a", ".", "a"},
+			{"a", ".", "no"},
+			{"a:b", "a", "no"},
+		},
+		"ObjectKey with delimiter": {
+			{"a:b", "a", "b"},
+			{"a:b:c", "a:b", "c"},
+			{"a::b", "a:", "b"},
+			{"a/b", "a", "no"},
+		},
+	}
+	impls := map[string]P{
+		"Unix":                     Unix,
+		"Windows":                  Windows,
+		"Slash":                    Slash,
+		"ObjectKey":                ObjectKey{},
+		"ObjectKey with delimiter": ObjectKey{Delimiter: ":"},
+	}
+
+	for implName, tests := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			p := impls[implName]
+			for _, test := range tests {
+				t.Run(test.path+" "+test.prefix, func(t *testing.T) {
+					got, ok := TrimPathPrefix(p, test.path, test.prefix)
+					if !ok {
+						if got != test.path {
+							t.Errorf("wrong result for TrimPathPrefix(%q, %q) with no prefix: %s", test.path, test.prefix, got)
+						}
+						got = "no"
+					}
+					if got != test.want {
+						t.Errorf("wrong result for TrimPathPrefix(%q, %q)\ngot:  %s\nwant: %s", test.path, test.prefix, got, test.want)
+					}
+
+					if got, want := HasPathPrefix(p, test.path, test.prefix), test.want != "no"; got != want {
+						t.Errorf("wrong result for HasPathPrefix(%q, %q)\ngot:  %t\nwant: %t", test.path, test.prefix, got, want)
+					}
+					if got, want := IsWithin(p, test.path, test.prefix), test.want != "no" && test.want != "." && test.want != ""; got != want {
+						t.Errorf("wrong result for IsWithin(%q, %q)\ngot:  %t\nwant: %t", test.path, test.prefix, got, want)
+					}
+				})
+			}
+		})
+	}
+}
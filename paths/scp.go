@@ -0,0 +1,126 @@
+package paths
+
+import (
+	"strings"
+)
+
+// SCPPath is a path in the "[user@]host:path" form accepted by scp, rsync
+// and similar tools, or a local path given in the same position.
+type SCPPath struct {
+	// User is the user name to log in as, or empty for the default.
+	User string
+
+	// Host is the name or address of the remote host, or empty if the path
+	// is local. An IPv6 address is written without brackets.
+	Host string
+
+	// Port is the TCP port of the remote host, or empty for the default.
+	Port string
+
+	// P is the path implementation for Path.
+	P P
+
+	// Path is the path on the host, which may be relative to the home
+	// directory of the user on the remote host, or empty for the home
+	// directory itself.
+	Path string
+}
+
+// ParseSCPPath parses a path in the "[user@]host:path" form. The host may be
+// an IPv6 address in brackets, and a port may follow a bracketed host, as in
+// "user@[::1]:2222:path" or "[server]:2222:path".
+//
+// As with scp, the string is a local path rather than a remote one if it
+// contains no colon, or if it contains a slash before the first colon. A
+// string that starts with a Windows drive letter followed by a colon and
+// a separator, like `C:\x` or "C:/x", is also a local path, and so is never
+// mistaken for a path on a host named "C". A single-letter host can still be
+// written with a path that does not start with a separator, like "c:dir".
+//
+// Path is bound to Windows if it starts with a drive letter, as in
+// `host:C:\x`, and otherwise to the given implementation, which should
+// usually be Unix for remote paths. A nil implementation means Unix.
+//
+// Returns a *RemotePathError if the user or host is empty, or if a bracketed
+// host is malformed.
+func ParseSCPPath(p P, s string) (SCPPath, error) {
+	if p == nil {
+		p = Unix
+	}
+	if isSCPDrive(s) {
+		return SCPPath{P: Windows, Path: s}, nil
+	}
+	colon := strings.IndexByte(s, ':')
+	if colon < 0 || strings.IndexByte(s[:colon], '/') >= 0 {
+		return SCPPath{P: p, Path: s}, nil
+	}
+
+	var ret SCPPath
+	rest := s
+	if at := strings.LastIndexByte(s[:colon], '@'); at >= 0 {
+		ret.User, rest = s[:at], s[at+1:]
+		if ret.User == "" {
+			return SCPPath{}, &RemotePathError{Path: s, Err: ErrEmptyUser}
+		}
+	}
+	if strings.HasPrefix(rest, "[") {
+		end := strings.IndexByte(rest, ']')
+		if end < 0 || end+1 >= len(rest) || rest[end+1] != ':' {
+			return SCPPath{}, &RemotePathError{Path: s, Err: ErrUnclosedHost}
+		}
+		ret.Host, rest = rest[1:end], rest[end+2:]
+		if port, path, ok := strings.Cut(rest, ":"); ok && port != "" && strings.Trim(port, "0123456789") == "" {
+			ret.Port, rest = port, path
+		}
+	} else {
+		ret.Host, rest, _ = strings.Cut(rest, ":")
+	}
+	if ret.Host == "" {
+		return SCPPath{}, &RemotePathError{Path: s, Err: ErrEmptyHost}
+	}
+
+	ret.P, ret.Path = p, rest
+	if isSCPDrive(rest) {
+		ret.P = Windows
+	}
+	return ret, nil
+}
+
+// IsRemote returns true if the path refers to a remote host.
+func (sp SCPPath) IsRemote() bool {
+	return sp.Host != ""
+}
+
+// String returns the path in the "[user@]host:path" form, or just the path
+// if it is local. The host is written in brackets if it is an IPv6 address or
+// if there is a port.
+func (sp SCPPath) String() string {
+	if sp.Host == "" {
+		return sp.Path
+	}
+	var buf strings.Builder
+	if sp.User != "" {
+		buf.WriteString(sp.User)
+		buf.WriteByte('@')
+	}
+	if sp.Port != "" || strings.Contains(sp.Host, ":") {
+		buf.WriteByte('[')
+		buf.WriteString(sp.Host)
+		buf.WriteByte(']')
+	} else {
+		buf.WriteString(sp.Host)
+	}
+	buf.WriteByte(':')
+	if sp.Port != "" {
+		buf.WriteString(sp.Port)
+		buf.WriteByte(':')
+	}
+	buf.WriteString(sp.Path)
+	return buf.String()
+}
+
+// isSCPDrive returns true if the given path starts with a Windows drive
+// letter followed by a colon and then either a separator or nothing.
+func isSCPDrive(path string) bool {
+	return len(path) >= 2 && path[1] == ':' && isWindowsDrive(path)
+}
@@ -0,0 +1,80 @@
+package paths
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSCPPath(t *testing.T) {
+	tests := []struct {
+		s    string
+		want SCPPath
+		back string
+		err  error
+	}{
+		{"host:dir/a", SCPPath{Host: "host", P: Unix, Path: "dir/a"}, "", nil},
+		{"alice@host:/srv/a", SCPPath{User: "alice", Host: "host", P: Unix, Path: "/srv/a"}, "", nil},
+		{"host:", SCPPath{Host: "host", P: Unix}, "", nil},
+		{"host:a:b", SCPPath{Host: "host", P: Unix, Path: "a:b"}, "", nil},
+		{"alice@[::1]:dir", SCPPath{User: "alice", Host: "::1", P: Unix, Path: "dir"}, "", nil},
+		{"[fe80::1%eth0]:/x", SCPPath{Host: "fe80::1%eth0", P: Unix, Path: "/x"}, "", nil},
+		{"[host]:2222:/srv", SCPPath{Host: "host", Port: "2222", P: Unix, Path: "/srv"}, "", nil},
+		{"alice@[::1]:22:dir", SCPPath{User: "alice", Host: "::1", Port: "22", P: Unix, Path: "dir"}, "", nil},
+		{"[host]:/srv", SCPPath{Host: "host", P: Unix, Path: "/srv"}, "host:/srv", nil},
+		{`winhost:C:\Users\alice`, SCPPath{Host: "winhost", P: Windows, Path: `C:\Users\alice`}, "", nil},
+		{"bob@winhost:D:/data", SCPPath{User: "bob", Host: "winhost", P: Windows, Path: "D:/data"}, "", nil},
+		{"c:dir", SCPPath{Host: "c", P: Unix, Path: "dir"}, "", nil},
+		{`C:\x`, SCPPath{P: Windows, Path: `C:\x`}, "", nil},
+		{"C:/x", SCPPath{P: Windows, Path: "C:/x"}, "", nil},
+		{"C:", SCPPath{P: Windows, Path: "C:"}, "", nil},
+		{"dir/a", SCPPath{P: Unix, Path: "dir/a"}, "", nil},
+		{"./host:a", SCPPath{P: Unix, Path: "./host:a"}, "", nil},
+		{"@host:a", SCPPath{}, "", ErrEmptyUser},
+		{":a", SCPPath{}, "", ErrEmptyHost},
+		{"alice@:a", SCPPath{}, "", ErrEmptyHost},
+		{"[::1]dir", SCPPath{}, "", ErrUnclosedHost},
+		{"[::1", SCPPath{}, "", ErrUnclosedHost},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			got, err := ParseSCPPath(nil, test.s)
+			if test.err != nil {
+				var pathErr *RemotePathError
+				if !errors.As(err, &pathErr) || pathErr.Path != test.s {
+					t.Fatalf("wrong error for ParseSCPPath(%q)\ngot:  %v\nwant: a RemotePathError", test.s, err)
+				}
+				if !errors.Is(err, test.err) {
+					t.Errorf("wrong error for ParseSCPPath(%q)\ngot:  %v\nwant: %v", test.s, err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for ParseSCPPath(%q): %s", test.s, err)
+			}
+			if got != test.want {
+				t.Errorf("wrong result for ParseSCPPath(%q)\ngot:  %#v\nwant: %#v", test.s, got, test.want)
+			}
+			if got.IsRemote() != (test.want.Host != "") {
+				t.Errorf("wrong result from IsRemote for %q", test.s)
+			}
+			want := test.back
+			if want == "" {
+				want = test.s
+			}
+			if got.String() != want {
+				t.Errorf("wrong result from String\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+
+	t.Run("default implementation", func(t *testing.T) {
+		got, err := ParseSCPPath(Windows, `host:dir\a`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.P != Windows || got.P.Base(got.Path) != "a" {
+			t.Errorf("wrong result for ParseSCPPath with Windows\ngot:  %#v", got)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package paths
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// SFTPURL returns the sftp:// or ssh:// URL for the given remote path, as
+// described by the SSH URI scheme draft:
+//
+//	sftp://[user@]host[:port][/path]
+//
+// The path is converted from the syntax of sp.P. An absolute path appears
+// unchanged, apart from its separators, and a relative path appears under
+// "/~/", which stands for the home directory of the user, as in
+// "sftp://user@host/~/dir". An empty path produces "/~/" itself. A path
+// starting with "~/" or "~user/" is also relative to a home directory, and so
+// "~alice/dir" produces "/~alice/dir".
+//
+// For Windows, a path with a drive letter uses the convention of the Windows
+// port of OpenSSH, with a slash before the drive letter, and so `C:\x`
+// produces "sftp://host/C:/x".
+//
+// The scheme must be "sftp" or "ssh", in any case, and the URL uses its
+// lowercase form. Returns a *URLError with reason BadScheme for any other
+// scheme, MissingHost if sp is not remote, or UNCPath for a Windows UNC path,
+// which has no equivalent in the OpenSSH convention.
+func SFTPURL(scheme string, sp SCPPath) (*url.URL, error) {
+	scheme = strings.ToLower(scheme)
+	if scheme != "sftp" && scheme != "ssh" {
+		return nil, &URLError{Reason: BadScheme, Scheme: "sftp"}
+	}
+	fail := func(reason URLErrorReason) (*url.URL, error) {
+		return nil, &URLError{Reason: reason, Scheme: scheme}
+	}
+	if sp.Host == "" {
+		return fail(MissingHost)
+	}
+	p := sp.P
+	if p == nil {
+		p = Unix
+	}
+
+	path := sp.Path
+	if syntaxOf(p) == windowsImpl {
+		im := windowsImpl
+		path = windowsTrimLongPrefix(path)
+		if im.isUNC(path) {
+			return fail(UNCPath)
+		}
+		path = im.toSlash(path)
+	}
+	switch {
+	case path == "":
+		path = "/~/"
+	case strings.HasPrefix(path, "~"):
+		path = "/" + path
+	case isSCPDrive(path) && syntaxOf(p) == windowsImpl:
+		path = "/" + path
+		if len(path) == 3 {
+			path += "/"
+		}
+	case !strings.HasPrefix(path, "/"):
+		path = "/~/" + path
+	}
+
+	host := sp.Host
+	if sp.Port != "" || strings.Contains(host, ":") {
+		host = net.JoinHostPort(host, sp.Port)
+		host = strings.TrimSuffix(host, ":")
+	}
+	u := &url.URL{
+		Scheme: scheme,
+		Host:   host,
+		Path:   path,
+	}
+	if sp.User != "" {
+		u.User = url.User(sp.User)
+	}
+	return u, nil
+}
+
+// FromSFTPURL is the inverse of SFTPURL, returning the remote path identified
+// by the given sftp:// or ssh:// URL, with its path in the syntax of the
+// given implementation for the remote host.
+//
+// A path under "/~/" is returned as a path relative to the user's home
+// directory, and "/~user/" as a path starting with "~user". For Windows, a
+// path like "/C:/x" produces `C:\x`. Any password or connection parameters
+// in the user portion of the URL, as in "user;fingerprint=...", are
+// discarded.
+//
+// Returns a *URLError with reason BadScheme if the URL's scheme is not sftp
+// or ssh, or MissingHost if it has no host.
+func FromSFTPURL(p P, u *url.URL) (SCPPath, error) {
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "sftp" && scheme != "ssh" {
+		return SCPPath{}, &URLError{URL: u, Reason: BadScheme, Scheme: "sftp"}
+	}
+	fail := func(reason URLErrorReason) (SCPPath, error) {
+		return SCPPath{}, &URLError{URL: u, Reason: reason, Scheme: scheme}
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fail(MissingHost)
+	}
+	if p == nil {
+		p = Unix
+	}
+
+	ret := SCPPath{Host: host, Port: u.Port(), P: p}
+	if u.User != nil {
+		ret.User, _, _ = strings.Cut(u.User.Username(), ";")
+	}
+
+	path := u.Path
+	switch {
+	case path == "" || path == "/~" || path == "/~/":
+		path = ""
+	case strings.HasPrefix(path, "/~/"):
+		path = path[len("/~/"):]
+	case strings.HasPrefix(path, "/~"):
+		path = path[1:]
+	case syntaxOf(p) == windowsImpl && isSCPDrive(path[1:]):
+		path = path[1:]
+		if len(path) == 2 {
+			path += "/"
+		}
+	}
+	if syntaxOf(p) == windowsImpl {
+		path = windowsImpl.fromSlash(path)
+	}
+	if path != "" {
+		path = p.Clean(path)
+	}
+	ret.Path = path
+	return ret, nil
+}
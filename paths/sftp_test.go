@@ -0,0 +1,155 @@
+package paths
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestSFTPURL(t *testing.T) {
+	type Test struct {
+		url  string
+		want SCPPath
+		back string
+	}
+
+	tests := map[string]struct {
+		p     P
+		tests []Test
+	}{
+		"Unix": {
+			Unix,
+			[]Test{
+				{"sftp://host/srv/a", SCPPath{Host: "host", Path: "/srv/a"}, ""},
+				{"sftp://alice@host/~/dir", SCPPath{User: "alice", Host: "host", Path: "dir"}, ""},
+				{"sftp://alice@host/~/", SCPPath{User: "alice", Host: "host"}, ""},
+				{"sftp://alice@host/~", SCPPath{User: "alice", Host: "host"}, "sftp://alice@host/~/"},
+				{"sftp://alice@host", SCPPath{User: "alice", Host: "host"}, "sftp://alice@host/~/"},
+				{"sftp://host/~bob/dir", SCPPath{Host: "host", Path: "~bob/dir"}, ""},
+				{"sftp://host/~/a/../b/", SCPPath{Host: "host", Path: "b"}, "sftp://host/~/b"},
+				{"ssh://host:2222/srv/a%20b", SCPPath{Host: "host", Port: "2222", Path: "/srv/a b"}, ""},
+				{"SFTP://alice;fingerprint=ssh-rsa-aa@host/x", SCPPath{User: "alice", Host: "host", Path: "/x"}, "sftp://alice@host/x"},
+				{"sftp://[::1]:22/x", SCPPath{Host: "::1", Port: "22", Path: "/x"}, ""},
+				{"sftp://host/C:/x", SCPPath{Host: "host", Path: "/C:/x"}, ""},
+			},
+		},
+		"Windows": {
+			Windows,
+			[]Test{
+				{"sftp://host/C:/x", SCPPath{Host: "host", Path: `C:\x`}, ""},
+				{"sftp://alice@host/C:/Users/alice/", SCPPath{User: "alice", Host: "host", Path: `C:\Users\alice`}, "sftp://alice@host/C:/Users/alice"},
+				{"sftp://host/D:", SCPPath{Host: "host", Path: `D:\`}, "sftp://host/D:/"},
+				{"sftp://host/~/dir/a", SCPPath{Host: "host", Path: `dir\a`}, ""},
+				{"sftp://host/x", SCPPath{Host: "host", Path: `\x`}, ""},
+			},
+		},
+	}
+
+	for name, implTest := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, test := range implTest.tests {
+				t.Run(test.url, func(t *testing.T) {
+					u, err := url.Parse(test.url)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got, err := FromSFTPURL(implTest.p, u)
+					if err != nil {
+						t.Fatalf("unexpected error for FromSFTPURL(%q): %s", test.url, err)
+					}
+					want := test.want
+					want.P = implTest.p
+					if got != want {
+						t.Errorf("wrong result for FromSFTPURL(%q)\ngot:  %#v\nwant: %#v", test.url, got, want)
+					}
+
+					back, err := SFTPURL(u.Scheme, got)
+					if err != nil {
+						t.Fatalf("unexpected error from SFTPURL: %s", err)
+					}
+					wantURL := test.back
+					if wantURL == "" {
+						wantURL = test.url
+					}
+					if back.String() != wantURL {
+						t.Errorf("wrong result from SFTPURL\ngot:  %s\nwant: %s", back, wantURL)
+					}
+				})
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for _, test := range []struct {
+			url    *url.URL
+			reason URLErrorReason
+			scheme string
+		}{
+			{&url.URL{Scheme: "file", Host: "host", Path: "/x"}, BadScheme, "sftp"},
+			{&url.URL{Scheme: "sftp", Path: "/x"}, MissingHost, "sftp"},
+			{&url.URL{Scheme: "SSH", Path: "/x"}, MissingHost, "ssh"},
+		} {
+			_, err := FromSFTPURL(Unix, test.url)
+			if !errors.Is(err, test.reason) {
+				t.Errorf("wrong error for FromSFTPURL(%q): %v", test.url, err)
+			}
+			if urlErr, ok := err.(*URLError); ok && urlErr.Scheme != test.scheme {
+				t.Errorf("wrong scheme in error for FromSFTPURL(%q)\ngot:  %s\nwant: %s", test.url, urlErr.Scheme, test.scheme)
+			}
+		}
+		for _, test := range []struct {
+			scheme string
+			sp     SCPPath
+			reason URLErrorReason
+		}{
+			{"https", SCPPath{Host: "host", Path: "/x"}, BadScheme},
+			{"sftp", SCPPath{Path: "/x"}, MissingHost},
+			{"ssh", SCPPath{Path: "/x"}, MissingHost},
+			{"sftp", SCPPath{Host: "host", P: Windows, Path: `\\server\share\x`}, UNCPath},
+			{"sftp", SCPPath{Host: "host", P: Windows, Path: `\\?\UNC\server\share\x`}, UNCPath},
+		} {
+			_, err := SFTPURL(test.scheme, test.sp)
+			if !errors.Is(err, test.reason) {
+				t.Errorf("wrong error for SFTPURL(%q, %#v): %v", test.scheme, test.sp, err)
+			}
+			want := test.scheme
+			if test.reason == BadScheme {
+				want = "sftp"
+			}
+			if urlErr, ok := err.(*URLError); ok && urlErr.Scheme != want {
+				t.Errorf("wrong scheme in error for SFTPURL(%q, %#v)\ngot:  %s\nwant: %s", test.scheme, test.sp, urlErr.Scheme, want)
+			}
+		}
+	})
+
+	t.Run("scheme case", func(t *testing.T) {
+		u, err := SFTPURL("SFTP", SCPPath{Host: "host", Path: "/x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := u.String(), "sftp://host/x"; got != want {
+			t.Errorf("wrong result from SFTPURL\ngot:  %s\nwant: %s", got, want)
+		}
+		sp, err := FromSFTPURL(Unix, &url.URL{Scheme: "SSH", Host: "host", Path: "/x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := sp.Path, "/x"; got != want {
+			t.Errorf("wrong result from FromSFTPURL\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+
+	t.Run("from SCP path", func(t *testing.T) {
+		sp, err := ParseSCPPath(Unix, `alice@winhost:C:\Users\alice`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := SFTPURL("sftp", sp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := u.String(), "sftp://alice@winhost/C:/Users/alice"; got != want {
+			t.Errorf("wrong result from SFTPURL\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package paths
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// SMBLocation describes a file or directory on an SMB share, as identified
+// by an smb:// URL, along with the connection details that an smb:// URL can
+// carry but a Windows UNC path cannot.
+type SMBLocation struct {
+	// UNC is the Windows UNC path of the share or of a file within it,
+	// like `\\server\share\dir`.
+	UNC string
+
+	// Domain and User are the authentication domain and user name, which
+	// appear in a URL like "smb://DOMAIN;user@server/share". Either or both
+	// may be empty.
+	Domain, User string
+
+	// Port is the TCP port of the server, or empty for the default.
+	Port string
+}
+
+// FromSMBURL returns the location identified by the given smb:// URL, in
+// the form described by the SMB URI scheme draft:
+//
+//	smb://[[domain;]user[:password]@]server[:port]/share[/path]
+//
+// Any password in the URL is discarded. An IPv6 address for the server
+// is converted into the "ipv6-literal.net" form that Windows accepts in
+// UNC paths.
+//
+// Returns a *URLError with reason BadScheme if the URL does not use the smb
+// scheme, MissingHost if it has no server, or MissingShare if it does not
+// include a share.
+func FromSMBURL(u *url.URL) (SMBLocation, error) {
+	fail := func(reason URLErrorReason) (SMBLocation, error) {
+		return SMBLocation{}, &URLError{URL: u, Reason: reason, Scheme: "smb"}
+	}
+	if !strings.EqualFold(u.Scheme, "smb") {
+		return fail(BadScheme)
+	}
+	host := u.Hostname()
+	if strings.Contains(host, ":") {
+		host = ipv6LiteralNet(host)
+	}
+	if host == "" {
+		return fail(MissingHost)
+	}
+	im := windowsImpl
+	unc := im.Clean(`\\` + host + im.fromSlash(u.Path))
+	if !im.isUNC(unc) {
+		return fail(MissingShare)
+	}
+
+	loc := SMBLocation{UNC: unc, Port: u.Port()}
+	if u.User != nil {
+		loc.User = u.User.Username()
+		if domain, user, ok := strings.Cut(loc.User, ";"); ok {
+			loc.Domain, loc.User = domain, user
+		}
+	}
+	return loc, nil
+}
+
+// URL returns the smb:// URL for the location. This is the inverse of
+// FromSMBURL, except that a server in the "ipv6-literal.net" form becomes
+// an IPv6 address in brackets.
+//
+// Returns a *URLError with reason MissingShare if UNC is not a UNC path.
+func (l SMBLocation) URL() (*url.URL, error) {
+	host, rest, ok := windowsImpl.windowsUNCHost(l.UNC)
+	if !ok {
+		return nil, &URLError{Reason: MissingShare, Scheme: "smb"}
+	}
+	if addr, ok := fromIPv6LiteralNet(host); ok {
+		host = addr
+	}
+	if l.Port != "" || strings.Contains(host, ":") {
+		host = net.JoinHostPort(host, l.Port)
+		host = strings.TrimSuffix(host, ":")
+	}
+
+	u := &url.URL{
+		Scheme: "smb",
+		Host:   host,
+		Path:   rest,
+	}
+	user := l.User
+	if l.Domain != "" {
+		user = l.Domain + ";" + user
+	}
+	if user != "" {
+		u.User = url.User(user)
+	}
+	return u, nil
+}
+
+// CIFSMount returns the Mount for a cifs filesystem with the given source,
+// as it appears in /etc/fstab or /proc/mounts in the form
+// "//server/share/path", mounted at the given mount point.
+//
+// The second return value is false if the source does not include both
+// a server and a share.
+func CIFSMount(source, mountPoint string) (Mount, bool) {
+	im := windowsImpl
+	host, rest, ok := im.windowsUNCHost(source)
+	if !ok {
+		return Mount{}, false
+	}
+	return Mount{Host: host, Remote: rest, Local: mountPoint}, true
+}
+
+// CIFSMountPath returns the local path of the file with the given UNC path
+// on a Unix system that mounts SMB shares at the given mount points, as with
+// the cifs filesystem on Linux, using the given path implementation for the
+// mount points.
+//
+// The server, share and path within the share are compared using the case
+// rules of Windows, as is usual for SMB servers, and the mount with the
+// longest Remote path that contains the UNC path is used. The second return
+// value is false if no mount contains the UNC path.
+func CIFSMountPath(p P, mounts []Mount, unc string) (string, bool) {
+	m, rest := longestMount(mounts, func(m *Mount) ([]string, bool) {
+		return trimComponents(Windows, unc, `\\`+m.Host+m.Remote)
+	})
+	if m == nil {
+		return "", false
+	}
+	return p.Join(append([]string{m.Local}, rest...)...), true
+}
+
+// CIFSUNCPath is the inverse of CIFSMountPath, returning the UNC path of the
+// file with the given local path, using the mount with the longest Local
+// path that contains it. The second return value is false if no mount
+// contains the local path.
+func CIFSUNCPath(p P, mounts []Mount, path string) (string, bool) {
+	m, rest := longestMount(mounts, func(m *Mount) ([]string, bool) {
+		return trimComponents(p, path, m.Local)
+	})
+	if m == nil {
+		return "", false
+	}
+	return Windows.Join(append([]string{`\\` + m.Host + m.Remote}, rest...)...), true
+}
+
+// ipv6LiteralNet returns the name in the "ipv6-literal.net" domain that
+// Windows uses to represent the given IPv6 address in a UNC path, with
+// colons replaced by hyphens and a zone separator replaced by "s".
+func ipv6LiteralNet(addr string) string {
+	addr = strings.ReplaceAll(addr, ":", "-")
+	addr = strings.ReplaceAll(addr, "%", "s")
+	return addr + ".ipv6-literal.net"
+}
+
+// fromIPv6LiteralNet is the inverse of ipv6LiteralNet. The second return value
+// is false if the name is not in the "ipv6-literal.net" domain.
+func fromIPv6LiteralNet(name string) (string, bool) {
+	const suffix = ".ipv6-literal.net"
+	if len(name) <= len(suffix) || !strings.EqualFold(name[len(name)-len(suffix):], suffix) {
+		return "", false
+	}
+	addr := name[:len(name)-len(suffix)]
+	addr = strings.ReplaceAll(addr, "-", ":")
+	addr = strings.Replace(addr, "s", "%", 1)
+	return addr, true
+}
@@ -0,0 +1,153 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ReadLinkFS is the interface implemented by a filesystem that supports
+// symbolic links, as required by EvalSymlinks.
+//
+// This has the same method set as fs.ReadLinkFS from Go 1.25 and later, so
+// any implementation of that interface is also an implementation of this one.
+type ReadLinkFS interface {
+	fs.FS
+
+	// ReadLink returns the destination of the named symbolic link, which
+	// is interpreted as a native path for the implementation passed to
+	// EvalSymlinks.
+	ReadLink(name string) (string, error)
+
+	// Lstat returns a FileInfo describing the named file, without
+	// following it if it is a symbolic link.
+	Lstat(name string) (fs.FileInfo, error)
+}
+
+// maxSymlinks is the maximum number of symbolic links that EvalSymlinks will
+// follow while resolving a single path, to detect loops.
+const maxSymlinks = 255
+
+// EvalSymlinks returns the given native path after evaluating any symbolic
+// links it traverses in the given filesystem, whose root directory "."
+// corresponds to the native path root. A relative path is interpreted as
+// relative to root.
+//
+// Symbolic links are resolved one path component at a time, using the Join
+// and Clean rules of the given path implementation, and so a ".." component
+// following a symbolic link refers to the parent of the link's destination
+// rather than the parent of the link itself. Relative link destinations are
+// relative to the directory containing the link.
+//
+// Once a symbolic link has been followed, resolution behaves as if root were
+// the root directory of the filesystem, as for a process confined to it
+// with chroot. Absolute link destinations are relative to root, and a ".."
+// component at root refers to root itself. Before that, the path itself is
+// interpreted lexically, and so it may pass through root or its ancestors,
+// as in "/srv/image/../image/lib". For example, if fsys is a view of an extracted Unix image whose
+// root is "/srv/image", then a link to "/usr/lib" refers to "usr/lib" in
+// fsys, which has the native path "/srv/image/usr/lib". For Windows, an
+// absolute destination may include a volume name only if it is the same as
+// the volume name of root.
+//
+// The result is an absolute, cleaned, native path within root. Returns an
+// error if a component of the path does not exist, if the path or its
+// resolved form is not within root, if a link destination is on another
+// volume, or if it encounters more than 255 symbolic links, which usually
+// indicates a loop.
+func EvalSymlinks(p P, fsys ReadLinkFS, root, path string) (string, error) {
+	if !p.IsAbs(path) {
+		path = p.Join(root, path)
+	}
+	im := syntaxOf(p)
+
+	dest, rest := splitRoot(p, path)
+	links := 0
+	for rest != "" {
+		// Take the next component from the front of rest
+		i := 0
+		for i < len(rest) && !im.isPathSeparator(rest[i]) {
+			i++
+		}
+		elem := rest[:i]
+		for i < len(rest) && im.isPathSeparator(rest[i]) {
+			i++
+		}
+		rest = rest[i:]
+
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			if within, ok := trimComponents(p, dest, root); links == 0 || !ok || len(within) != 0 {
+				dest = p.Dir(dest)
+			}
+			continue
+		}
+
+		next := p.Join(dest, elem)
+		if _, ok := trimComponents(p, root, next); ok {
+			// The root itself and its ancestors aren't visible in fsys, so
+			// we must assume that they are directories. The result is
+			// checked against root below.
+			dest = next
+			continue
+		}
+		name, err := fsName(p, root, next)
+		if err != nil {
+			return "", err
+		}
+		info, err := fsys.Lstat(name)
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			dest = next
+			continue
+		}
+
+		links++
+		if links > maxSymlinks {
+			return "", errors.New("EvalSymlinks: too many links")
+		}
+		target, err := fsys.ReadLink(name)
+		if err != nil {
+			return "", err
+		}
+		if target == "" {
+			return "", fmt.Errorf("EvalSymlinks: symbolic link %s has an empty destination", next)
+		}
+
+		// The link destination replaces the component we just consumed,
+		// and so the remainder of the path is now relative to it.
+		targetRoot, targetRest := splitRoot(p, target)
+		if targetRoot != "" {
+			// Absolute destinations are relative to root
+			if vol := p.VolumeName(targetRoot); vol != "" && !sameWord(p, vol, p.VolumeName(root)) {
+				return "", fmt.Errorf("EvalSymlinks: symbolic link %s refers to %s, which is not on the same volume as %s", next, target, root)
+			}
+			dest = p.Clean(root)
+		}
+		if rest != "" {
+			targetRest += string(im.separator()) + rest
+		}
+		rest = targetRest
+	}
+	dest = p.Clean(dest)
+	if _, ok := trimComponents(p, dest, root); !ok {
+		return "", fmt.Errorf("EvalSymlinks: path %s is not within %s", dest, root)
+	}
+	return dest, nil
+}
+
+// splitRoot splits the given path into its root, consisting of its volume
+// name and any leading separators, and the remainder. The root of a relative
+// path is empty unless it has a volume name.
+func splitRoot(p P, path string) (root, rest string) {
+	im := syntaxOf(p)
+	n := len(p.VolumeName(path))
+	for n < len(path) && im.isPathSeparator(path[n]) {
+		n++
+	}
+	return path[:n], path[n:]
+}
@@ -0,0 +1,178 @@
+package paths
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestEvalSymlinks(t *testing.T) {
+	type Test struct {
+		path, want string
+	}
+
+	unixFS := linkMapFS{
+		"usr/lib/libc.so":      {},
+		"usr/lib64":            {Mode: fs.ModeSymlink, Data: []byte("lib")},
+		"lib":                  {Mode: fs.ModeSymlink, Data: []byte("/usr/lib")},
+		"etc/alternatives/cc":  {Mode: fs.ModeSymlink, Data: []byte("../../usr/bin/gcc")},
+		"usr/bin/gcc":          {},
+		"usr/bin/cc":           {Mode: fs.ModeSymlink, Data: []byte("/etc/alternatives/cc")},
+		"home/alice/proj/src":  {Mode: fs.ModeDir},
+		"home/alice/link":      {Mode: fs.ModeSymlink, Data: []byte("proj/src")},
+		"home/alice/loop1":     {Mode: fs.ModeSymlink, Data: []byte("loop2")},
+		"home/alice/loop2":     {Mode: fs.ModeSymlink, Data: []byte("loop1")},
+		"home/alice/self":      {Mode: fs.ModeSymlink, Data: []byte("./self/x")},
+		"home/alice/dangling":  {Mode: fs.ModeSymlink, Data: []byte("nonexistent")},
+		"home/alice/outside":   {Mode: fs.ModeSymlink, Data: []byte("../../../..")},
+		"home/alice/up":        {Mode: fs.ModeSymlink, Data: []byte("proj/src/..")},
+		"home/alice/bslash":    {Mode: fs.ModeSymlink, Data: []byte(`a\b`)},
+		"home/alice/a\\b":      {},
+		"home/alice/usrlink":   {Mode: fs.ModeSymlink, Data: []byte("//usr///lib/")},
+		"home/alice/proj/file": {},
+	}
+
+	unixTests := []Test{
+		{"/usr/lib/libc.so", "/usr/lib/libc.so"},
+		{"/usr/lib64/libc.so", "/usr/lib/libc.so"},
+		{"/lib/libc.so", "/usr/lib/libc.so"},
+		{"/usr/bin/cc", "/usr/bin/gcc"},
+		{"/home/alice/link", "/home/alice/proj/src"},
+		{"/home/alice/link/../file", "/home/alice/proj/file"},
+		{"/home/alice/link/../../link", "/home/alice/proj/src"},
+		{"/home/alice/up/file", "/home/alice/proj/file"},
+		{"/home/alice/usrlink/libc.so", "/usr/lib/libc.so"},
+		{"/home/alice/bslash", `/home/alice/a\b`},
+		{"/home/alice/./proj//file", "/home/alice/proj/file"},
+		{"home/alice/link", "/home/alice/proj/src"},
+		{"/../../usr/lib64", "/usr/lib"},
+		{"/", "/"},
+		{"/home/alice/outside", "/"},
+		{"/home/alice/loop1", "err"},
+		{"/home/alice/self", "err"},
+		{"/home/alice/dangling", "err"},
+		{"/home/alice/missing", "err"},
+	}
+
+	t.Run("Unix", func(t *testing.T) {
+		for _, test := range unixTests {
+			t.Run(test.path, func(t *testing.T) {
+				testEvalSymlinks(t, Unix, unixFS, "/", test.path, test.want)
+			})
+		}
+	})
+
+	t.Run("Unix subtree root", func(t *testing.T) {
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv/image/home/alice/link", "/srv/image/home/alice/proj/src")
+
+		// Absolute destinations are relative to the root, as with chroot.
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv/image/lib", "/srv/image/usr/lib")
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv/image/usr/bin/cc", "/srv/image/usr/bin/gcc")
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv/image/home/alice/usrlink/libc.so", "/srv/image/usr/lib/libc.so")
+
+		// A ".." component at the root refers to the root itself.
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv/image/home/alice/outside", "/srv/image")
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv/image/lib/../../../etc", "/srv/image/etc")
+
+		// The path itself must still be within the root, but is
+		// interpreted lexically until it follows a link.
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/usr/lib", "err")
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv", "err")
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/", "err")
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv/image/..", "err")
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv/image", "/srv/image")
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv/image/../image/lib", "/srv/image/usr/lib")
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv/other/../image/lib", "err")
+		testEvalSymlinks(t, Unix, unixFS, "/srv/image", "/srv/image/home/../../image/usr/bin/cc", "/srv/image/usr/bin/gcc")
+	})
+
+	windowsFS := linkMapFS{
+		"Users/Alice/Documents/a.txt": {},
+		"Users/Alice/Docs":            {Mode: fs.ModeSymlink, Data: []byte(`Documents`)},
+		"Users/Alice/Abs":             {Mode: fs.ModeSymlink, Data: []byte(`C:\Users\Alice\Documents`)},
+		"Users/Alice/Rooted":          {Mode: fs.ModeSymlink, Data: []byte(`\Users\Alice\Docs\`)},
+		"Users/Alice/Slashed":         {Mode: fs.ModeSymlink, Data: []byte(`../Alice/Documents`)},
+		"Users/Alice/Other":           {Mode: fs.ModeSymlink, Data: []byte(`D:\data`)},
+	}
+
+	windowsTests := []Test{
+		{`C:\Users\Alice\Docs\a.txt`, `C:\Users\Alice\Documents\a.txt`},
+		{`C:/Users/Alice/Docs/a.txt`, `C:\Users\Alice\Documents\a.txt`},
+		{`C:\Users\Alice\Abs\a.txt`, `C:\Users\Alice\Documents\a.txt`},
+		{`C:\Users\Alice\Rooted\a.txt`, `C:\Users\Alice\Documents\a.txt`},
+		{`C:\Users\Alice\Slashed\a.txt`, `C:\Users\Alice\Documents\a.txt`},
+		{`Users\Alice\Docs`, `C:\Users\Alice\Documents`},
+		{`C:\Users\Alice\Other`, `err`},
+	}
+
+	t.Run("Windows", func(t *testing.T) {
+		for _, test := range windowsTests {
+			t.Run(test.path, func(t *testing.T) {
+				testEvalSymlinks(t, Windows, windowsFS, `C:\`, test.path, test.want)
+			})
+		}
+	})
+
+	t.Run("Windows subtree root", func(t *testing.T) {
+		testEvalSymlinks(t, Windows, windowsFS, `c:\image`, `C:\image\Users\Alice\Abs\a.txt`, `c:\image\Users\Alice\Documents\a.txt`)
+		testEvalSymlinks(t, Windows, windowsFS, `C:\image`, `C:\image\Users\Alice\Rooted\a.txt`, `C:\image\Users\Alice\Documents\a.txt`)
+		testEvalSymlinks(t, Windows, windowsFS, `C:\image`, `C:\image\Users\Alice\Other`, `err`)
+		testEvalSymlinks(t, Windows, windowsFS, `C:\image`, `c:\IMAGE\..\image\Users\Alice\Docs`, `c:\image\Users\Alice\Documents`)
+		testEvalSymlinks(t, Windows, windowsFS, `C:\image`, `C:\`, `err`)
+	})
+}
+
+func testEvalSymlinks(t *testing.T, p P, fsys ReadLinkFS, root, path, want string) {
+	t.Helper()
+	got, err := EvalSymlinks(p, fsys, root, path)
+	if want == "err" {
+		if err == nil {
+			t.Errorf("wrong result for EvalSymlinks(%q, %q)\ngot:  %s\nwant: an error", root, path, got)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error for EvalSymlinks(%q, %q): %s", root, path, err)
+	}
+	if got != want {
+		t.Errorf("wrong result for EvalSymlinks(%q, %q)\ngot:  %s\nwant: %s", root, path, got, want)
+	}
+}
+
+// linkMapFS is an fstest.MapFS that also implements ReadLinkFS, treating
+// the data of any file with fs.ModeSymlink as its destination.
+type linkMapFS fstest.MapFS
+
+var _ ReadLinkFS = linkMapFS(nil)
+
+func (fsys linkMapFS) Open(name string) (fs.File, error) {
+	return fstest.MapFS(fsys).Open(name)
+}
+
+func (fsys linkMapFS) ReadLink(name string) (string, error) {
+	f, ok := fsys[name]
+	if !ok || f.Mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return string(f.Data), nil
+}
+
+func (fsys linkMapFS) Lstat(name string) (fs.FileInfo, error) {
+	if f, ok := fsys[name]; ok && f.Mode&fs.ModeSymlink != 0 {
+		return linkInfo{name: name, f: f}, nil
+	}
+	return fs.Stat(fstest.MapFS(fsys), name)
+}
+
+type linkInfo struct {
+	name string
+	f    *fstest.MapFile
+}
+
+func (i linkInfo) Name() string       { return i.name }
+func (i linkInfo) Size() int64        { return int64(len(i.f.Data)) }
+func (i linkInfo) Mode() fs.FileMode  { return i.f.Mode }
+func (i linkInfo) ModTime() time.Time { return i.f.ModTime }
+func (i linkInfo) IsDir() bool        { return false }
+func (i linkInfo) Sys() interface{}   { return i.f.Sys }
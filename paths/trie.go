@@ -0,0 +1,238 @@
+package paths
+
+import (
+	"iter"
+	"slices"
+)
+
+// PathTrie is a tree of paths, each associated with a value of type V,
+// organized by path components so that it can efficiently find the
+// entries that are ancestors or descendents of a given path.
+//
+// Paths are cleaned and their components compared using the case rules of a
+// particular path implementation, as for PathMap, and each entry remembers
+// the spelling of its path as it was first inserted. The top level of the
+// tree is the volume name of each path, along with whether it is rooted,
+// so a relative path is never an ancestor of an absolute path, and for
+// Windows `C:\a` is never an ancestor of `D:\a\b`.
+//
+// Use NewPathTrie to create a PathTrie. A PathTrie is not safe for
+// concurrent use.
+type PathTrie[V any] struct {
+	p    P
+	root trieNode[V]
+	len  int
+}
+
+type trieNode[V any] struct {
+	// path is the spelling of the path of this node's entry, set only if
+	// the node has an entry.
+	path  string
+	value V
+	set   bool
+
+	// children is keyed by the wordKey of each child's path component, or
+	// by trieTopKey for the children of the root.
+	children map[string]*trieNode[V]
+}
+
+// NewPathTrie returns a new, empty PathTrie that uses the given path
+// implementation.
+func NewPathTrie[V any](p P) *PathTrie[V] {
+	return &PathTrie[V]{p: p}
+}
+
+// Insert associates the given value with the given path, replacing any value
+// already associated with an equivalent path. If there is already such a
+// path then the trie retains its original spelling.
+func (t *PathTrie[V]) Insert(path string, v V) {
+	node := &t.root
+	for _, key := range t.keys(path) {
+		child := node.children[key]
+		if child == nil {
+			if node.children == nil {
+				node.children = make(map[string]*trieNode[V])
+			}
+			child = &trieNode[V]{}
+			node.children[key] = child
+		}
+		node = child
+	}
+	if !node.set {
+		node.path = path
+		node.set = true
+		t.len++
+	}
+	node.value = v
+}
+
+// Get returns the value associated with the given path, or with an
+// equivalent path. The second return value is false if there is no
+// such path.
+func (t *PathTrie[V]) Get(path string) (V, bool) {
+	node := &t.root
+	for _, key := range t.keys(path) {
+		node = node.children[key]
+		if node == nil {
+			var zero V
+			return zero, false
+		}
+	}
+	return node.value, node.set
+}
+
+// Delete removes the given path, or an equivalent path, from the trie,
+// returning false if there was no such path. Entries for paths within the
+// given path are not affected.
+func (t *PathTrie[V]) Delete(path string) bool {
+	keys := t.keys(path)
+	nodes := make([]*trieNode[V], 0, len(keys)+1)
+	nodes = append(nodes, &t.root)
+	for _, key := range keys {
+		node := nodes[len(nodes)-1].children[key]
+		if node == nil {
+			return false
+		}
+		nodes = append(nodes, node)
+	}
+	node := nodes[len(nodes)-1]
+	if !node.set {
+		return false
+	}
+	*node = trieNode[V]{children: node.children}
+	t.len--
+
+	// Remove any nodes that no longer lead to an entry
+	for i := len(keys) - 1; i >= 0; i-- {
+		node := nodes[i+1]
+		if node.set || len(node.children) != 0 {
+			break
+		}
+		delete(nodes[i].children, keys[i])
+	}
+	return true
+}
+
+// Len returns the number of paths in the trie.
+func (t *PathTrie[V]) Len() int {
+	return t.len
+}
+
+// LongestPrefix returns the entry for the given path or for its nearest
+// ancestor that has an entry, comparing whole components. The third return
+// value is false if neither the path nor any of its ancestors has an entry.
+//
+// For example, if the trie contains "/home" and "/home/alice" then the
+// longest prefix of "/home/alice/proj" is "/home/alice", while the longest
+// prefix of "/home/alicia" is "/home".
+func (t *PathTrie[V]) LongestPrefix(path string) (string, V, bool) {
+	var found *trieNode[V]
+	for node := range t.walk(path) {
+		if node.set {
+			found = node
+		}
+	}
+	if found == nil {
+		var zero V
+		return "", zero, false
+	}
+	return found.path, found.value, true
+}
+
+// Prefixes returns an iterator over the entries for the given path and all
+// of its ancestors that have entries, starting with the shortest.
+func (t *PathTrie[V]) Prefixes(path string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		for node := range t.walk(path) {
+			if node.set && !yield(node.path, node.value) {
+				return
+			}
+		}
+	}
+}
+
+// Subtree returns an iterator over the entries for the given path and all of
+// the paths within it, in the order described for ComparePaths.
+//
+// The trie must not be modified during iteration.
+func (t *PathTrie[V]) Subtree(path string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		node := &t.root
+		for _, key := range t.keys(path) {
+			node = node.children[key]
+			if node == nil {
+				return
+			}
+		}
+		node.all(yield)
+	}
+}
+
+// All returns an iterator over all of the entries in the trie, in the order
+// described for ComparePaths.
+//
+// The trie must not be modified during iteration.
+func (t *PathTrie[V]) All() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		t.root.all(yield)
+	}
+}
+
+// walk returns an iterator over the nodes along the given path, starting
+// with the node for its volume, stopping at the first component that is
+// not in the trie.
+func (t *PathTrie[V]) walk(path string) iter.Seq[*trieNode[V]] {
+	return func(yield func(*trieNode[V]) bool) {
+		node := &t.root
+		for _, key := range t.keys(path) {
+			node = node.children[key]
+			if node == nil || !yield(node) {
+				return
+			}
+		}
+	}
+}
+
+// all calls yield for the node's entry, if any, and then for each of its
+// descendents in order, returning false if yield returns false.
+func (n *trieNode[V]) all(yield func(string, V) bool) bool {
+	if n.set && !yield(n.path, n.value) {
+		return false
+	}
+	keys := make([]string, 0, len(n.children))
+	for key := range n.children {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if !n.children[key].all(yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// keys returns the keys of the nodes along the given path, the first of
+// which is the key for its volume.
+func (t *PathTrie[V]) keys(path string) []string {
+	vol, rooted, elems := splitComponents(t.p, path)
+	keys := make([]string, 0, len(elems)+1)
+	keys = append(keys, trieTopKey(t.p, vol, rooted))
+	for _, elem := range elems {
+		keys = append(keys, wordKey(t.p, elem))
+	}
+	return keys
+}
+
+// trieTopKey returns the key for the top-level node of a trie for paths with
+// the given volume name that are either rooted or relative. Sorting these
+// keys gives the same order as ComparePaths: by volume name and then with
+// relative paths first. The volume name is followed by a byte that sorts
+// before any character of a volume name, rather than by a separator, so
+// that `\` for rooted paths without a volume name sorts before "C:".
+func trieTopKey(p P, vol string, rooted bool) string {
+	if rooted {
+		return wordKey(p, vol) + "\x01"
+	}
+	return wordKey(p, vol) + "\x00"
+}
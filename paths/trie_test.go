@@ -0,0 +1,175 @@
+package paths
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestPathTrieLongestPrefix(t *testing.T) {
+	type Test struct {
+		path, want string
+	}
+
+	implTests := map[string]struct {
+		entries []string
+		tests   []Test
+	}{
+		"Unix": {
+			[]string{"/", "/home", "/home/alice", "/srv/data/", "proj"},
+			[]Test{
+				{"/home/alice/proj", "/home/alice"},
+				{"/home/alice", "/home/alice"},
+				{"/home/alicia", "/home"},
+				{"/home/Alice/proj", "/home"},
+				{"/home/../srv/data/x", "/srv/data/"},
+				{"/srv", "/"},
+				{"proj/src", "proj"},
+				{"./proj", "proj"},
+				{"other", ""},
+			},
+		},
+		"Windows": {
+			[]string{`C:\Users`, `c:\users\alice`, `D:\`, `\\server\share\dir`, `C:rel`},
+			[]Test{
+				{`C:\USERS\ALICE\Documents`, `c:\users\alice`},
+				{`c:/users/bob`, `C:\Users`},
+				{`C:\Windows`, ""},
+				{`D:\Users\alice`, `D:\`},
+				{`\\SERVER\Share\Dir\file`, `\\server\share\dir`},
+				{`\\server\share`, ""},
+				{`\\server\other\dir`, ""},
+				{`C:rel\x`, `C:rel`},
+				{`rel\x`, ""},
+				{`\Users\alice`, ""},
+			},
+		},
+	}
+	impls := map[string]P{
+		"Unix":    Unix,
+		"Windows": Windows,
+	}
+
+	for implName, implTest := range implTests {
+		t.Run(implName, func(t *testing.T) {
+			trie := NewPathTrie[string](impls[implName])
+			for _, entry := range implTest.entries {
+				trie.Insert(entry, entry)
+			}
+			for _, test := range implTest.tests {
+				t.Run(test.path, func(t *testing.T) {
+					got, v, ok := trie.LongestPrefix(test.path)
+					if !ok {
+						got = ""
+					} else if v != got {
+						t.Errorf("wrong value %q for %q", v, got)
+					}
+					if got != test.want {
+						t.Errorf("wrong result for LongestPrefix(%q)\ngot:  %s\nwant: %s", test.path, got, test.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestPathTrieIteration(t *testing.T) {
+	trie := NewPathTrie[int](Windows)
+	entries := []string{
+		`C:\a\b`,
+		`C:\a-b`,
+		`C:\A`,
+		`C:\a\B\c`,
+		`D:\x`,
+		`C:\b`,
+		`rel`,
+		`\root`,
+		`C:rel`,
+		`\\server\share\x`,
+	}
+	for i, entry := range entries {
+		trie.Insert(entry, i)
+	}
+	if got, want := trie.Len(), len(entries); got != want {
+		t.Errorf("wrong length %d; want %d", got, want)
+	}
+
+	collect := func(seq func(func(string, int) bool)) []string {
+		var ret []string
+		for path := range seq {
+			ret = append(ret, path)
+		}
+		return ret
+	}
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{
+			"All",
+			collect(trie.All()),
+			[]string{`rel`, `\root`, `C:rel`, `C:\A`, `C:\a\b`, `C:\a\B\c`, `C:\a-b`, `C:\b`, `D:\x`, `\\server\share\x`},
+		},
+		{
+			"Subtree",
+			collect(trie.Subtree(`c:\a`)),
+			[]string{`C:\A`, `C:\a\b`, `C:\a\B\c`},
+		},
+		{
+			"Subtree without entry",
+			collect(trie.Subtree(`C:\`)),
+			[]string{`C:\A`, `C:\a\b`, `C:\a\B\c`, `C:\a-b`, `C:\b`},
+		},
+		{
+			"Subtree missing",
+			collect(trie.Subtree(`C:\z`)),
+			nil,
+		},
+		{
+			"Prefixes",
+			collect(trie.Prefixes(`C:\A\B\C\D`)),
+			[]string{`C:\A`, `C:\a\b`, `C:\a\B\c`},
+		},
+	}
+	sorted := slices.Clone(entries)
+	slices.SortFunc(sorted, func(a, b string) int {
+		return ComparePaths(Windows, a, b)
+	})
+	if got := collect(trie.All()); !reflect.DeepEqual(got, sorted) {
+		t.Errorf("All is not in the order of ComparePaths\ngot:  %q\nwant: %q", got, sorted)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !reflect.DeepEqual(test.got, test.want) {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", test.got, test.want)
+			}
+		})
+	}
+
+	t.Run("Delete", func(t *testing.T) {
+		if trie.Delete(`C:\a\b\c\d`) {
+			t.Errorf("Delete returned true for a path with no entry")
+		}
+		if !trie.Delete(`c:\a\b`) {
+			t.Errorf("Delete returned false for an existing entry")
+		}
+		if !trie.Delete(`C:\A\B\C`) {
+			t.Errorf("Delete returned false for an existing entry")
+		}
+		if _, ok := trie.Get(`C:\a\b`); ok {
+			t.Errorf("deleted entry still present")
+		}
+		if v, ok := trie.Get(`c:\a`); !ok || v != 2 {
+			t.Errorf("wrong result for Get after Delete: %d, %t", v, ok)
+		}
+		if got, want := trie.Len(), len(entries)-2; got != want {
+			t.Errorf("wrong length %d; want %d", got, want)
+		}
+		if children := trie.root.children[trieTopKey(Windows, "C:", true)].children[wordKey(Windows, "a")].children; len(children) != 0 {
+			t.Errorf("empty nodes were not removed: %#v", children)
+		}
+	})
+}
@@ -1,7 +1,7 @@
 package paths
 
 import (
-	"errors"
+	"fmt"
 	"net/url"
 	"strings"
 )
@@ -41,14 +41,32 @@ func (im impl) unixFromURL(u *url.URL) (string, error) {
 		return im.Clean(u.Path), nil
 	case "file":
 		if u.Host != "" && !strings.EqualFold(u.Host, "localhost") {
-			return "", errors.New("only local file: URLs are allowed")
+			return "", &URLError{URL: u, Reason: NonLocalHost}
 		}
 		if u.User != nil {
-			return "", errors.New("user portion not allowed in file: URLs")
+			return "", &URLError{URL: u, Reason: UserInfo}
 		}
 		// We'll tolerate and ignore query and fragment parts
 		return im.Clean(u.Path), nil
 	default:
-		return "", errors.New("file: is the only allowed URL scheme")
+		return "", &URLError{URL: u, Reason: BadScheme}
 	}
 }
+
+func (im impl) unixSplitList(list string) []string {
+	if list == "" {
+		return []string{}
+	}
+	// Empty entries are significant on Unix systems, where they refer to
+	// the current working directory, so we preserve them.
+	return strings.Split(list, string(im.listSeparator()))
+}
+
+func (im impl) unixJoinList(elems []string) (string, error) {
+	for _, e := range elems {
+		if strings.IndexByte(e, im.listSeparator()) >= 0 {
+			return "", fmt.Errorf("path %q cannot be included in a list because it contains %q", e, im.listSeparator())
+		}
+	}
+	return strings.Join(elems, string(im.listSeparator())), nil
+}
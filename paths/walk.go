@@ -0,0 +1,74 @@
+package paths
+
+import (
+	"io/fs"
+	"iter"
+	"strings"
+)
+
+// WalkDir walks the entire file tree of the given filesystem, calling fn for
+// each file or directory in the tree, including the root, in the same order
+// as fs.WalkDir.
+//
+// The filesystem's root directory "." is taken to correspond to the given
+// native root path, and so the path passed to fn is the native path of each
+// file, written in the syntax of the given path implementation. For example,
+// with the Windows implementation and the root `C:\data`, the file named
+// "logs/a.txt" in fsys is passed to fn as `C:\data\logs\a.txt`.
+//
+// The function fn may return fs.SkipDir or fs.SkipAll to control the walk,
+// and errors are reported to fn in the same way as for fs.WalkDir.
+func WalkDir(p P, root string, fsys fs.FS, fn fs.WalkDirFunc) error {
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		return fn(nativeName(p, root, name), d, err)
+	})
+}
+
+// WalkEntry is an entry produced by WalkDirSeq, describing one file or
+// directory along with its native path.
+//
+// As for the argument to an fs.WalkDirFunc, the DirEntry is nil if the walk
+// could not stat the root directory, and so calling a method such as Name
+// on the entry would panic. An entry produced with a non-nil error should
+// be identified by its Path instead.
+type WalkEntry struct {
+	fs.DirEntry
+
+	// Path is the native path of the entry, as would be passed to the
+	// callback function of WalkDir.
+	Path string
+}
+
+// WalkDirSeq is a variant of WalkDir that returns an iterator over the
+// entries in the tree, rather than calling a function.
+//
+// If an error occurs while walking, the iterator produces the error along
+// with the entry that caused it, as would be passed to a WalkDir callback.
+// That includes an entry with a nil DirEntry if the root cannot be read, as
+// described for WalkEntry.
+// The walk continues after an error unless the loop stops, skipping the
+// contents of any directory that could not be read.
+//
+// There is no equivalent of fs.SkipDir for an iterator, but stopping the loop
+// early stops the walk, like fs.SkipAll.
+func WalkDirSeq(p P, root string, fsys fs.FS) iter.Seq2[WalkEntry, error] {
+	return func(yield func(WalkEntry, error) bool) {
+		WalkDir(p, root, fsys, func(path string, d fs.DirEntry, err error) error {
+			if !yield(WalkEntry{DirEntry: d, Path: path}, err) {
+				return fs.SkipAll
+			}
+			return nil
+		})
+	}
+}
+
+// nativeName is the inverse of fsName, returning the native path that
+// corresponds to the given io/fs name for a filesystem whose root directory
+// "." corresponds to the native path root.
+func nativeName(p P, root, name string) string {
+	if name == "." {
+		return p.Clean(root)
+	}
+	elems := append([]string{root}, strings.Split(name, "/")...)
+	return p.Join(elems...)
+}
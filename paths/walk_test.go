@@ -0,0 +1,173 @@
+package paths
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWalkDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt":          {},
+		"logs/1.log":     {},
+		"logs/2.log":     {},
+		"skip/x.txt":     {},
+		"sub/deep/y.txt": {},
+	}
+
+	tests := map[string]struct {
+		p    P
+		root string
+		want []string
+	}{
+		"Unix": {
+			Unix, "/srv/data",
+			[]string{
+				"/srv/data",
+				"/srv/data/a.txt",
+				"/srv/data/logs",
+				"/srv/data/logs/1.log",
+				"/srv/data/logs/2.log",
+				"/srv/data/skip",
+				"/srv/data/sub",
+				"/srv/data/sub/deep",
+				"/srv/data/sub/deep/y.txt",
+			},
+		},
+		"Windows": {
+			Windows, `C:/data/`,
+			[]string{
+				`C:\data`,
+				`C:\data\a.txt`,
+				`C:\data\logs`,
+				`C:\data\logs\1.log`,
+				`C:\data\logs\2.log`,
+				`C:\data\skip`,
+				`C:\data\sub`,
+				`C:\data\sub\deep`,
+				`C:\data\sub\deep\y.txt`,
+			},
+		},
+		"Windows UNC": {
+			Windows, `\\server\share`,
+			[]string{
+				`\\server\share`,
+				`\\server\share\a.txt`,
+				`\\server\share\logs`,
+				`\\server\share\logs\1.log`,
+				`\\server\share\logs\2.log`,
+				`\\server\share\skip`,
+				`\\server\share\sub`,
+				`\\server\share\sub\deep`,
+				`\\server\share\sub\deep\y.txt`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			err := WalkDir(test.p, test.root, fsys, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				got = append(got, path)
+				if d.Name() == "skip" {
+					return fs.SkipDir
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong paths\ngot:  %q\nwant: %q", got, test.want)
+			}
+		})
+	}
+
+	t.Run("SkipAll", func(t *testing.T) {
+		var got []string
+		err := WalkDir(Unix, "/srv", fsys, func(path string, d fs.DirEntry, err error) error {
+			got = append(got, path)
+			if path == "/srv/logs/1.log" {
+				return fs.SkipAll
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		want := []string{"/srv", "/srv/a.txt", "/srv/logs", "/srv/logs/1.log"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong paths\ngot:  %q\nwant: %q", got, want)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		wantErr := errors.New("stop")
+		err := WalkDir(Unix, "/srv", fsys, func(path string, d fs.DirEntry, err error) error {
+			if path == "/srv/logs" {
+				return wantErr
+			}
+			return nil
+		})
+		if err != wantErr {
+			t.Errorf("wrong error\ngot:  %v\nwant: %v", err, wantErr)
+		}
+	})
+}
+
+func TestWalkDirSeq(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt":      {},
+		"logs/1.log": {},
+		"logs/2.log": {},
+	}
+
+	var got []string
+	for entry, err := range WalkDirSeq(Windows, `D:\backup`, fsys) {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		got = append(got, entry.Path)
+		if entry.Name() == "1.log" {
+			break
+		}
+	}
+	want := []string{`D:\backup`, `D:\backup\a.txt`, `D:\backup\logs`, `D:\backup\logs\1.log`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong paths\ngot:  %q\nwant: %q", got, want)
+	}
+
+	var errs int
+	for entry, err := range WalkDirSeq(Unix, "/", fstest.MapFS{}) {
+		if err != nil || entry.Path != "/" {
+			errs++
+		}
+	}
+	if errs != 0 {
+		t.Errorf("unexpected errors walking an empty filesystem")
+	}
+
+	for entry, err := range WalkDirSeq(Unix, "/", errFS{}) {
+		if err == nil {
+			t.Errorf("no error for unreadable root")
+		}
+		if entry.Path != "/" {
+			t.Errorf("wrong path %q for root error", entry.Path)
+		}
+		if entry.DirEntry != nil {
+			t.Errorf("unexpected DirEntry %v for root error", entry.DirEntry)
+		}
+	}
+}
+
+// errFS is an fs.FS that fails to open any file.
+type errFS struct{}
+
+func (errFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
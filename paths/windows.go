@@ -1,7 +1,6 @@
 package paths
 
 import (
-	"errors"
 	"fmt"
 	"net/url"
 	"strings"
@@ -84,6 +83,8 @@ func (im impl) windowsToURL(path string) *url.URL {
 	// as understood by the Windows shell and Internet Explorer:
 	// https://blogs.msdn.microsoft.com/ie/2006/12/06/file-uris-in-windows/
 
+	// As for Unix, the URL is for the cleaned path.
+	path = im.Clean(path)
 	if !im.IsAbs(path) {
 		// A relative path becomes a schemeless URL.
 		return &url.URL{
@@ -91,16 +92,15 @@ func (im impl) windowsToURL(path string) *url.URL {
 		}
 	}
 
-	if im.isUNC(path) {
+	if host, rest, ok := im.windowsUNCHost(path); ok {
 		// For UNC paths, the hostname part goes in the hostname part of
 		// the file URI:
 		//  \\foo\bar\baz
 		//  file://foo/bar/baz
-		volLen := im.volumeNameLen(path)
 		return &url.URL{
 			Scheme: "file",
-			Host:   path[2:volLen],
-			Path:   path[volLen:],
+			Host:   host,
+			Path:   rest,
 		}
 	}
 
@@ -108,7 +108,7 @@ func (im impl) windowsToURL(path string) *url.URL {
 	// an empty hostname part.
 	return &url.URL{
 		Scheme: "file",
-		Path:   im.toSlash(path),
+		Path:   "/" + im.toSlash(path),
 	}
 }
 
@@ -124,7 +124,7 @@ func (im impl) windowsFromURL(u *url.URL) (string, error) {
 	case "file":
 		// We'll tolerate and ignore query and fragment parts
 		if u.User != nil {
-			return "", errors.New("user portion not allowed in file: URLs")
+			return "", &URLError{URL: u, Reason: UserInfo}
 		}
 		if u.Host != "" {
 			// If a hostname is present then it's a UNC path.
@@ -138,14 +138,77 @@ func (im impl) windowsFromURL(u *url.URL) (string, error) {
 			p = fmt.Sprintf("%s:%s", p[:2], p[3:])
 		}
 		if len(p) < 4 || p[0] != '/' || p[2] != ':' {
-			return "", errors.New("local file: URLs must begin with a drive letter and then a colon in the path portion")
+			return "", &URLError{URL: u, Reason: MissingDrive}
 		}
 		return im.Clean(p[1:]), nil // trim off leading slash
 	default:
-		return "", errors.New("file: is the only allowed URL scheme")
+		return "", &URLError{URL: u, Reason: BadScheme}
 	}
 }
 
 func (im impl) isUNC(path string) bool {
 	return im.volumeNameLen(path) > 2
 }
+
+// windowsUNCHost splits a UNC path into its server name and the remainder
+// of the path as a slash-separated absolute path starting with the share
+// name, as used in the host and path portions of a URL. The final return
+// value is false if the path is not a UNC path.
+func (im impl) windowsUNCHost(path string) (host, rest string, ok bool) {
+	if !im.isUNC(path) {
+		return "", "", false
+	}
+	path = im.Clean(path)
+	vol := im.VolumeName(path)
+	host, share, _ := strings.Cut(vol[2:], `\`)
+	return host, "/" + share + im.toSlash(path[len(vol):]), true
+}
+
+func (im impl) windowsSplitList(list string) []string {
+	// Split list, respecting but removing quotes.
+	elems := []string{}
+	var buf strings.Builder
+	quo := false
+	for i := 0; i < len(list); i++ {
+		switch c := list[i]; {
+		case c == '"':
+			quo = !quo
+		case c == im.listSeparator() && !quo:
+			// Windows ignores empty entries, rather than treating them
+			// as the current directory as Unix systems do.
+			if buf.Len() != 0 {
+				elems = append(elems, buf.String())
+				buf.Reset()
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	if buf.Len() != 0 {
+		elems = append(elems, buf.String())
+	}
+	return elems
+}
+
+func (im impl) windowsJoinList(elems []string) (string, error) {
+	var buf strings.Builder
+	for _, e := range elems {
+		if e == "" {
+			continue
+		}
+		if strings.IndexByte(e, '"') >= 0 {
+			return "", fmt.Errorf("path %q cannot be included in a list because it contains a quote", e)
+		}
+		if buf.Len() != 0 {
+			buf.WriteByte(im.listSeparator())
+		}
+		if strings.IndexByte(e, im.listSeparator()) >= 0 {
+			buf.WriteByte('"')
+			buf.WriteString(e)
+			buf.WriteByte('"')
+			continue
+		}
+		buf.WriteString(e)
+	}
+	return buf.String(), nil
+}